@@ -0,0 +1,20 @@
+package di
+
+// RegisterAdapter makes Get of U convert entity T by f unless U is registered
+// already, e.g. *zap.Logger to *slog.Logger. Adapted value has no cleanup of
+// its own, it's cleaned up along with T.
+func RegisterAdapter[T, U any](c *Container, f func(T) (U, error)) {
+	RegisterNamedAdapter(c, "", f)
+}
+
+// RegisterNamedAdapter converts named entity T to U of the same name
+func RegisterNamedAdapter[T, U any](c *Container, name string, f func(T) (U, error)) {
+	SetNamedIfAbsent(c, name, OptSetupC(func(c *Container) (U, error) {
+		val, err := resolve[T](c, name)
+		if err != nil {
+			return empty[U](), err
+		}
+
+		return f(val)
+	}))
+}
@@ -0,0 +1,53 @@
+package di_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestRegisterAdapter(t *testing.T) {
+	var (
+		c       = di.New()
+		cleaned = 0
+	)
+
+	di.Set(c, di.OptSetup(func() (int, error) {
+		return 42, nil
+	}), di.OptCleanup(func(int) error {
+		cleaned++
+		return nil
+	}))
+	di.RegisterAdapter(c, func(val int) (string, error) {
+		return strconv.Itoa(val), nil
+	})
+
+	if val := di.Get[string](c); val != "42" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if err := c.Cleanup(); err != nil || cleaned != 1 {
+		t.Errorf("Unexpected: %v, %v", err, cleaned)
+	}
+}
+
+func TestRegisterAdapterExplicit(t *testing.T) {
+	var (
+		c       = di.New()
+		errTest = errors.New("test")
+	)
+
+	di.SetNamed(c, "answer", di.OptSetup(func() (string, error) { return "explicit", nil }))
+	di.RegisterNamedAdapter(c, "answer", func(val int) (string, error) { return "adapted", nil })
+	di.RegisterNamedAdapter(c, "answer", func(val string) (int, error) { return 0, errTest })
+
+	if val := di.GetNamed[string](c, "answer"); val != "explicit" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if _, err := di.TryGetNamed[int](c, "answer"); !errors.Is(err, errTest) {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
@@ -0,0 +1,10 @@
+package di
+
+// Alias makes entity registered under name resolvable under alias as well,
+// e.g. to rename "master" to "primary" without changing every GetNamed at
+// once. Alias follows promoted version of entity. Alias is registration, it
+// can't be added to built container.
+func Alias[T any](c *Container, name, alias string) {
+	c.checkSealed()
+	c.setRedirect(entityKey[T](c.named(alias)), entityKey[T](c.named(name)))
+}
@@ -0,0 +1,38 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestAlias(t *testing.T) {
+	type db string
+
+	c := di.New()
+
+	di.SetNamed(c, "primary", di.OptSetup(func() (db, error) {
+		return "primary", nil
+	}))
+	di.Alias[db](c, "primary", "master")
+
+	if primary, master := di.GetNamed[db](c, "primary"), di.GetNamed[db](c, "master"); primary != master {
+		t.Errorf("Unexpected: %v, %v", primary, master)
+	}
+
+	di.SetNamedVersion(c, "primary", "v2", di.OptSetup(func() (db, error) {
+		return "primary v2", nil
+	}))
+	di.PromoteNamed[db](c, "primary", "v2")
+
+	if val := di.GetNamed[db](c, "master"); val != "primary v2" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	di.Alias[db](c, "replica", "secondary")
+	di.Alias[db](c, "secondary", "replica")
+
+	if _, err := di.TryGetNamed[db](c, "replica"); err == nil {
+		t.Errorf("Error expected")
+	}
+}
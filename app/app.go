@@ -0,0 +1,164 @@
+// Package app is batteries-included entry point of service: it builds
+// container from modules, starts eager entities, serves health endpoints and
+// shuts down gracefully on signal.
+//
+//	func main() {
+//		err := app.New(app.Config(LoadConfig)).
+//			Modules(storage.NewModule(), api.NewModule()).
+//			HealthAddr(":8081").
+//			Run()
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/irr123/di"
+)
+
+// App runs container built from modules until signal
+type App struct {
+	modules         []func(*di.Container)
+	discovered      bool
+	healthAddr      string
+	signals         []os.Signal
+	shutdownTimeout time.Duration
+}
+
+// New app of modules registering entities, see di.Builder.Add
+func New(modules ...func(*di.Container)) *App {
+	return &App{
+		modules:         modules,
+		signals:         []os.Signal{os.Interrupt, syscall.SIGTERM},
+		shutdownTimeout: 30 * time.Second,
+	}
+}
+
+// Config module registers config loaded by load, it's loaded by Init, so
+// invalid config fails the start
+func Config[T any](load func() (T, error)) func(*di.Container) {
+	return func(c *di.Container) {
+		di.Set(c, di.OptSetup(load), di.OptEager[T]())
+	}
+}
+
+// Modules adds di.Module bundles to app
+func (a *App) Modules(modules ...di.Module) *App {
+	for _, m := range modules {
+		a.modules = append(a.modules, m.Install)
+	}
+
+	return a
+}
+
+// DiscoveredModules adds modules registered by di.RegisterModule, e.g. by
+// init of blank imported feature packages. They're discovered by Run.
+func (a *App) DiscoveredModules() *App {
+	a.discovered = true
+	return a
+}
+
+// HealthAddr sets address health endpoints are served on, /livez by
+// di.Container.Live and /readyz by di.Container.Ready, see ProbeHandler.
+// They aren't served by default.
+func (a *App) HealthAddr(addr string) *App {
+	a.healthAddr = addr
+	return a
+}
+
+// Signals which stop app, SIGINT and SIGTERM by default
+func (a *App) Signals(signals ...os.Signal) *App {
+	a.signals = signals
+	return a
+}
+
+// ShutdownTimeout bounds graceful shutdown, 30 seconds by default
+func (a *App) ShutdownTimeout(timeout time.Duration) *App {
+	a.shutdownTimeout = timeout
+	return a
+}
+
+// Run app until signal, see RunContext
+func (a *App) Run() error {
+	return a.RunContext(context.Background())
+}
+
+// RunContext builds container, see di.Builder, starts eager entities by Init
+// and serves health endpoints until ctx is done or signal is received. Then
+// container is cleaned up, which stops the entities.
+func (a *App) RunContext(ctx context.Context) error {
+	modules := a.modules
+	if a.discovered {
+		for _, m := range di.DiscoveredModules() {
+			modules = append(modules, m.Install)
+		}
+	}
+
+	c, err := di.NewBuilder().Add(modules...).Build()
+	if err != nil {
+		return fmt.Errorf("build: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, a.signals...)
+	defer stop()
+
+	var server *http.Server
+	if a.healthAddr != "" {
+		listener, err := net.Listen("tcp", a.healthAddr)
+		if err != nil {
+			return fmt.Errorf("health endpoint: %w", err)
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/livez", ProbeHandler(c.Live))
+		mux.Handle("/readyz", ProbeHandler(c.Ready))
+
+		server = &http.Server{Handler: mux, ReadHeaderTimeout: time.Second}
+		go func() { _ = server.Serve(listener) }()
+	}
+
+	runErr := c.Init(ctx)
+	if runErr == nil {
+		<-ctx.Done()
+	} else {
+		runErr = fmt.Errorf("init: %w", runErr)
+	}
+
+	return errors.Join(runErr, a.shutdown(c, server))
+}
+
+func (a *App) shutdown(c *di.Container, server *http.Server) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	errs := []error{}
+	if server != nil {
+		errs = append(errs, server.Shutdown(ctx))
+	}
+
+	return errors.Join(append(errs, c.CleanupContext(ctx))...)
+}
+
+// ProbeHandler responds 503 with the reason once probe fails, e.g. by
+// di.Container.Ready
+func ProbeHandler(probe func(context.Context) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := probe(r.Context()); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	})
+}
@@ -0,0 +1,120 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/irr123/di"
+	"github.com/irr123/di/app"
+)
+
+type config struct{ name string }
+
+func TestRun(t *testing.T) {
+	var (
+		log     = []string{}
+		started = make(chan struct{})
+	)
+
+	server := func(c *di.Container) {
+		di.Set(c, di.OptSetupC(func(c *di.Container) (*http.Server, error) {
+			log = append(log, "start "+di.Get[config](c).name)
+			close(started)
+			return &http.Server{}, nil
+		}), di.OptCleanup(func(*http.Server) error {
+			log = append(log, "stop")
+			return nil
+		}), di.OptEager[*http.Server]())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	err := app.New(
+		app.Config(func() (config, error) { return config{name: "api"}, nil }),
+		server,
+	).HealthAddr("127.0.0.1:0").ShutdownTimeout(time.Second).RunContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(log) != "[start api stop]" {
+		t.Errorf("Unexpected: %v", log)
+	}
+}
+
+func TestRunModules(t *testing.T) {
+	type (
+		greeter string
+		counter int
+	)
+
+	di.RegisterModule(di.NewModule("app_test.counter", func(c *di.Container) {
+		di.Set(c, di.OptSetup(func() (counter, error) { return 1, nil }))
+	}))
+
+	var (
+		got     = []string{}
+		started = make(chan struct{})
+		module  = di.NewModule("greeter", func(c *di.Container) {
+			di.Set(c, di.OptSetupC(func(c *di.Container) (greeter, error) {
+				got = append(got, fmt.Sprint("hello ", di.Get[counter](c)))
+				close(started)
+				return "hello", nil
+			}), di.OptEager[greeter]())
+		})
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	if err := app.New().Modules(module).DiscoveredModules().RunContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(got) != "[hello 1]" {
+		t.Errorf("Unexpected: %v", got)
+	}
+}
+
+func TestRunFailed(t *testing.T) {
+	errTest := errors.New("test")
+
+	err := app.New(app.Config(func() (config, error) { return config{}, errTest })).RunContext(context.Background())
+	if !errors.Is(err, errTest) {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
+
+func TestProbeHandler(t *testing.T) {
+	c := di.New()
+	c.ErrorBudget(1)
+	di.Set(c, di.OptSetup(func() (int, error) { return 0, errors.New("test") }))
+
+	handler := app.ProbeHandler(c.Live)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Unexpected: %v", rec.Code)
+	}
+
+	_, _ = di.TryGet[int](c)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Unexpected: %v", rec.Code)
+	}
+}
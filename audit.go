@@ -0,0 +1,71 @@
+package di
+
+import (
+	"reflect"
+	"sync"
+)
+
+type (
+	// AuditLog records which call sites resolved which entities, see
+	// Container.Audit
+	AuditLog struct {
+		mu      sync.Mutex
+		index   map[auditKey]int
+		records []AuditRecord
+	}
+	// AuditRecord is entity resolved at call site Count times
+	AuditRecord struct {
+		Entity Entity
+		// Site is file:line of Get call
+		Site  string
+		Count int
+	}
+	auditKey struct {
+		entity string
+		site   string
+	}
+)
+
+// Audit starts recording Gets made through c and its scopes, e.g. for
+// security review of which packages touch payments client. Distinct pairs
+// of entity and call site are kept, so memory is bounded by code size.
+func (c *Container) Audit() *AuditLog {
+	a := &AuditLog{index: make(map[auditKey]int)}
+	c.OnGet(a.record)
+
+	return a
+}
+
+func (a *AuditLog) record(e GetEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := auditKey{entity: e.Entity.String(), site: e.Site}
+	if i, ok := a.index[key]; ok {
+		a.records[i].Count++
+		return
+	}
+
+	a.index[key] = len(a.records)
+	a.records = append(a.records, AuditRecord{Entity: e.Entity, Site: e.Site, Count: 1})
+}
+
+// Records in order they were first seen
+func (a *AuditLog) Records() []AuditRecord {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]AuditRecord(nil), a.records...)
+}
+
+// AuditSites returns call sites which resolved named entity of type T
+func AuditSites[T any](a *AuditLog, name string) []string {
+	sites := []string{}
+	for _, r := range a.Records() {
+		if r.Entity.Type == reflect.TypeFor[T]() && r.Entity.Name == name {
+			sites = append(sites, r.Site)
+		}
+	}
+
+	return sites
+}
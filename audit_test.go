@@ -0,0 +1,58 @@
+package di_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestAudit(t *testing.T) {
+	type payments struct{}
+
+	c := di.New()
+	audit := c.Audit()
+
+	di.Set(c, di.OptSetup(func() (*payments, error) { return &payments{}, nil }))
+	di.Set(c, di.OptSetup(func() (string, error) {
+		di.Get[*payments](c)
+		return "checkout", nil
+	}))
+
+	for range 3 {
+		di.Get[*payments](c)
+	}
+	di.Get[string](c.BeginScope().Container)
+
+	sites := di.AuditSites[*payments](audit, "")
+	if len(sites) != 2 || !strings.Contains(sites[0], "audit_test.go") {
+		t.Errorf("Unexpected: %v", sites)
+	}
+
+	records := audit.Records()
+	if len(records) != 3 || records[0].Count != 3 {
+		t.Errorf("Unexpected: %v", records)
+	}
+}
+
+func TestAuditProvide(t *testing.T) {
+	type (
+		db   struct{}
+		repo struct{ db *db }
+	)
+
+	c := di.New()
+	audit := c.Audit()
+
+	di.Set(c, di.OptSetup(func() (*db, error) { return &db{}, nil }))
+	if err := di.Provide(c, func(db *db) *repo { return &repo{db: db} }); err != nil {
+		t.Fatal(err)
+	}
+
+	di.Get[*repo](c)
+
+	sites := di.AuditSites[*db](audit, "")
+	if len(sites) != 1 || !strings.Contains(sites[0], "audit_test.go") {
+		t.Errorf("Unexpected: %v", sites)
+	}
+}
@@ -0,0 +1,32 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// OptAutoBindInterfaces makes entity resolvable by Get of any interface it
+// implements, unless the interface is registered explicitly, so consumers
+// could depend on interfaces without explicit registrations of them. Entity
+// and the interface share instance. Name is matched as well, two entities
+// of the same name implementing requested interface make Get fail.
+func OptAutoBindInterfaces[T any]() func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.autoBind = true }
+}
+
+// autoBound entities of name implementing iface
+func (c *Container) autoBound(iface reflect.Type, name string) ([]Entity, bool) {
+	bound := c.query(func(e Entity) bool {
+		return e.AutoBind && e.Name == name && e.Type.Implements(iface)
+	})
+
+	return bound, len(bound) > 0
+}
+
+func (c *Container) resolveBound(iface reflect.Type, bound []Entity) (any, error) {
+	if len(bound) > 1 {
+		return nil, fmt.Errorf("ambiguous %v: %v", iface, bound)
+	}
+
+	return c.resolve(bound[0].key)
+}
@@ -0,0 +1,65 @@
+package di_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+type autoBound struct{ closed int }
+
+func (a *autoBound) String() string { return "bound" }
+
+func (a *autoBound) Close() error {
+	a.closed++
+	return nil
+}
+
+func TestAutoBindInterfaces(t *testing.T) {
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (*autoBound, error) {
+		return &autoBound{}, nil
+	}), di.OptCleanup(func(a *autoBound) error {
+		return a.Close()
+	}), di.OptAutoBindInterfaces[*autoBound]())
+
+	stringer, closer := di.Get[fmt.Stringer](c), di.Get[io.Closer](c)
+	if stringer.String() != "bound" || closer != di.Get[*autoBound](c) {
+		t.Errorf("Unexpected: %v, %v", stringer, closer)
+	}
+
+	if _, err := di.TryGet[io.Reader](c); err == nil {
+		t.Errorf("Error expected")
+	}
+
+	if err := c.Cleanup(); err != nil || di.Get[*autoBound](c).closed != 1 {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
+
+func TestAutoBindInterfacesAmbiguous(t *testing.T) {
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (*autoBound, error) {
+		return &autoBound{}, nil
+	}), di.OptAutoBindInterfaces[*autoBound]())
+	di.Set(c, di.OptSetup(func() (*strings.Builder, error) {
+		return &strings.Builder{}, nil
+	}), di.OptAutoBindInterfaces[*strings.Builder]())
+
+	if _, err := di.TryGet[fmt.Stringer](c); err == nil {
+		t.Errorf("Error expected")
+	}
+
+	di.Set(c, di.OptSetup(func() (fmt.Stringer, error) {
+		return &strings.Builder{}, nil
+	}))
+
+	if _, err := di.TryGet[fmt.Stringer](c); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Builder assembles container from modules and validates it as a whole, so
+// broken wiring fails at startup rather than on the first Get
+type Builder struct {
+	modules []func(*Container)
+}
+
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Add modules registering entities, e.g. func(c *di.Container) { di.Set(c, ...) }.
+// Module may Set the same entity repeatedly to configure it, but entity
+// registered by several modules is reported by Build as duplicate.
+func (b *Builder) Add(modules ...func(*Container)) *Builder {
+	b.modules = append(b.modules, modules...)
+	return b
+}
+
+// Build new container from modules, it fails on duplicates and on missing or
+// cyclic dependencies declared by OptDependsOn, see Container.Validate.
+// Registrations of built container can't be changed, attempt to do so panics,
+// while its scopes are as usual.
+func (b *Builder) Build() (*Container, error) {
+	var (
+		c      = New()
+		errs   = []error{}
+		module int
+		owners = map[string]int{}
+	)
+
+	c.registering = func(entityName string) {
+		owner, ok := owners[entityName]
+		switch {
+		case !ok:
+			owners[entityName] = module
+		case owner != module:
+			errs = append(errs, fmt.Errorf("duplicate %s registered by modules %d and %d", entityName, owner+1, module+1))
+		}
+	}
+
+	for module = range b.modules {
+		b.modules[module](c)
+	}
+
+	c.registering = nil
+
+	if err := errors.Join(append(errs, c.Validate())...); err != nil {
+		return nil, err
+	}
+
+	c.sealed = true
+
+	return c, nil
+}
+
+// checkSealed panics on attempt to change registrations of built container
+func (c *Container) checkSealed() {
+	if c.sealed {
+		panic("di: registrations of built container can't be changed")
+	}
+}
+
+// checkRegistration of entity in container, it's called before Set changes it
+func (c *Container) checkRegistration(entityName string) {
+	c.checkSealed()
+	if c.registering != nil {
+		c.registering(entityName)
+	}
+}
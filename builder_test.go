@@ -0,0 +1,96 @@
+package di_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestBuilder(t *testing.T) {
+	type repo struct{ dsn string }
+
+	var (
+		config = func(c *di.Container) {
+			di.Set(c, di.OptSetup(func() (string, error) { return "dsn", nil }))
+		}
+		storage = func(c *di.Container) {
+			di.Set(c, di.OptSetupC(func(c *di.Container) (repo, error) {
+				return repo{dsn: di.Get[string](c)}, nil
+			}))
+			di.Set(c, di.OptDependsOn[repo](di.Dep[string]("")))
+		}
+	)
+
+	c, err := di.NewBuilder().Add(config, storage).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if val := di.Get[repo](c); val.dsn != "dsn" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	// scopes of built container accept registrations
+	scope := c.BeginScope()
+	di.Set(scope.Container, di.OptSetup(func() (string, error) { return "scoped", nil }))
+	if val := di.Get[string](scope.Container); val != "scoped" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Set should panic on built container")
+		}
+	}()
+	di.Set(c, di.OptSetup(func() (string, error) { return "late", nil }))
+}
+
+func TestBuilderValidation(t *testing.T) {
+	type repo struct{}
+
+	_, err := di.NewBuilder().Add(
+		func(c *di.Container) { di.Set(c, di.OptSetup(func() (string, error) { return "a", nil })) },
+		func(c *di.Container) { di.Set(c, di.OptSetup(func() (string, error) { return "b", nil })) },
+		func(c *di.Container) { di.Set(c, di.OptDependsOn[repo](di.Dep[int](""))) },
+	).Build()
+	if err == nil {
+		t.Fatal("Build should fail")
+	}
+
+	for _, expected := range []string{"duplicate", "modules 1 and 2", "not found"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Unexpected: %v", err)
+		}
+	}
+}
+
+func TestBuilderValidatesProvide(t *testing.T) {
+	type (
+		config struct{}
+		db     struct{}
+		params struct {
+			di.In
+			DB    *db
+			Cache *config `di:"optional"`
+		}
+	)
+
+	_, err := di.NewBuilder().Add(func(c *di.Container) {
+		if err := di.Provide(c, func(config) *db { return &db{} }, func(params) string { return "" }); err != nil {
+			t.Fatal(err)
+		}
+	}).Build()
+	if err == nil || !strings.Contains(err.Error(), "not found: <di_test.config") {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	_, err = di.NewBuilder().Add(func(c *di.Container) {
+		if err := di.Provide(c, func(*db) config { return config{} }, func(config) *db { return &db{} }); err != nil {
+			t.Fatal(err)
+		}
+	}).Build()
+	if err == nil || !strings.Contains(err.Error(), "dependency cycle") {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
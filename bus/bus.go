@@ -0,0 +1,145 @@
+// Package bus is in-process publish/subscribe of events managed by container.
+// Subscribers are entities, so they get their dependencies as usual, and
+// delivery is stopped by container Cleanup before subscribers are cleaned up.
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/irr123/di"
+)
+
+type (
+	// Handler of events of type E, its error is logged
+	Handler[E any] func(context.Context, E) error
+	// Bus delivers events of type E to each of subscribers by its own
+	// goroutine, so slow subscriber doesn't hold the others up
+	Bus[E any] struct {
+		mu          sync.RWMutex
+		closed      bool
+		done        chan struct{}
+		subscribers []*subscriber[E]
+		// publishing is number of Publish calls in flight, events are closed
+		// once they're done
+		publishing sync.WaitGroup
+		wg         sync.WaitGroup
+	}
+
+	subscriber[E any] struct {
+		name    string
+		handler Handler[E]
+		events  chan E
+	}
+)
+
+// ErrClosed is returned by Publish once container cleaned bus up
+var ErrClosed = errors.New("bus closed")
+
+// Register *Bus[E] entity, each subscriber buffers up to buffer events. Bus
+// is eager, so Container.Init starts delivery, and Cleanup stops it once
+// delivery of events published so far is done.
+func Register[E any](c *di.Container, buffer int) {
+	di.Set(c, di.OptSetupC(func(c *di.Container) (*Bus[E], error) {
+		b := &Bus[E]{done: make(chan struct{})}
+		for _, name := range di.ListNamed[Handler[E]](c) {
+			handler, err := di.TryGetNamed[Handler[E]](c, name)
+			if err != nil {
+				return nil, err
+			}
+
+			b.subscribers = append(b.subscribers, &subscriber[E]{
+				name:    name,
+				handler: handler,
+				events:  make(chan E, buffer),
+			})
+		}
+
+		for _, s := range b.subscribers {
+			b.wg.Add(1)
+			go b.deliver(s)
+		}
+
+		return b, nil
+	}), di.OptCleanup(func(b *Bus[E]) error {
+		b.close()
+		return nil
+	}), di.OptEager[*Bus[E]]())
+}
+
+// Subscribe registers handler of events of type E under name, setup could
+// get dependencies of handler from container. Subscribers have to be
+// registered before bus is built.
+func Subscribe[E any](c *di.Container, name string, setup func(*di.Container) (Handler[E], error)) {
+	di.SetNamed(c, name, di.OptSetupC(setup))
+}
+
+// Publish event to every subscriber, it blocks while buffer of any of them is
+// full until ctx is done or bus is closed
+func (b *Bus[E]) Publish(ctx context.Context, event E) error {
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrClosed
+	}
+
+	b.publishing.Add(1)
+	b.mu.RUnlock()
+
+	defer b.publishing.Done()
+
+	for _, s := range b.subscribers {
+		select {
+		case s.events <- event:
+		case <-ctx.Done():
+			return fmt.Errorf("publish to %s: %w", s.name, ctx.Err())
+		case <-b.done:
+			return fmt.Errorf("publish to %s: %w", s.name, ErrClosed)
+		}
+	}
+
+	return nil
+}
+
+func (b *Bus[E]) deliver(s *subscriber[E]) {
+	defer b.wg.Done()
+
+	for event := range s.events {
+		if err := s.handle(event); err != nil {
+			slog.Error("event handler failed", "subscriber", s.name, "error", err)
+		}
+	}
+}
+
+func (s *subscriber[E]) handle(event E) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return s.handler(context.Background(), event)
+}
+
+// close stops accepting events, aborts Publish calls blocked on full buffers
+// and waits for delivery of the accepted events
+func (b *Bus[E]) close() {
+	b.mu.Lock()
+	closing := !b.closed
+	b.closed = true
+	b.mu.Unlock()
+
+	if closing {
+		close(b.done)
+		b.publishing.Wait()
+
+		for _, s := range b.subscribers {
+			close(s.events)
+		}
+	}
+
+	b.wg.Wait()
+}
@@ -0,0 +1,130 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/irr123/di"
+	"github.com/irr123/di/bus"
+)
+
+type orderPlaced struct{ id int }
+
+func TestBus(t *testing.T) {
+	var (
+		c   = di.New()
+		mu  sync.Mutex
+		log = []string{}
+	)
+
+	bus.Register[orderPlaced](c, 1)
+	for _, name := range []string{"billing", "audit"} {
+		bus.Subscribe(c, name, func(c *di.Container) (bus.Handler[orderPlaced], error) {
+			return func(_ context.Context, e orderPlaced) error {
+				mu.Lock()
+				defer mu.Unlock()
+
+				log = append(log, fmt.Sprint(name, e.id))
+				return nil
+			}, nil
+		})
+	}
+
+	if err := c.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	b := di.Get[*bus.Bus[orderPlaced]](c)
+	for i := range 3 {
+		if err := b.Publish(context.Background(), orderPlaced{id: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := c.Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(log) != 6 {
+		t.Errorf("Events should be delivered before cleanup: %v", log)
+	}
+
+	if err := b.Publish(context.Background(), orderPlaced{}); !errors.Is(err, bus.ErrClosed) {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
+
+func TestBusBlockedPublish(t *testing.T) {
+	var (
+		c       = di.New()
+		release = make(chan struct{})
+	)
+
+	bus.Register[orderPlaced](c, 0)
+	bus.Subscribe(c, "slow", func(*di.Container) (bus.Handler[orderPlaced], error) {
+		return func(context.Context, orderPlaced) error {
+			<-release
+			return errors.New("logged")
+		}, nil
+	})
+
+	b := di.Get[*bus.Bus[orderPlaced]](c)
+	if err := b.Publish(context.Background(), orderPlaced{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := b.Publish(ctx, orderPlaced{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	close(release)
+
+	if err := c.Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBusPublishOnCleanup(t *testing.T) {
+	var (
+		c    = di.New()
+		b    *bus.Bus[orderPlaced]
+		errs = make(chan error, 1)
+	)
+
+	bus.Register[orderPlaced](c, 0)
+	bus.Subscribe(c, "echo", func(*di.Container) (bus.Handler[orderPlaced], error) {
+		return func(ctx context.Context, e orderPlaced) error {
+			// blocks, the only subscriber is busy with this event
+			errs <- b.Publish(ctx, orderPlaced{id: e.id + 1})
+			return nil
+		}, nil
+	})
+
+	b = di.Get[*bus.Bus[orderPlaced]](c)
+	if err := b.Publish(context.Background(), orderPlaced{}); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error)
+	go func() { done <- c.Cleanup() }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Cleanup should abort blocked Publish")
+	}
+
+	if err := <-errs; !errors.Is(err, bus.ErrClosed) {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
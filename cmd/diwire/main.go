@@ -0,0 +1,317 @@
+// Command diwire generates slices of providers listed by wire.NewSet calls of
+// package, so google/wire sets are registered by di.Provide without listing
+// providers twice while wire.Build injectors are being retired:
+//
+//	//go:generate go run github.com/irr123/di/cmd/diwire
+//
+//	var StorageSet = wire.NewSet(NewDB, NewRepo)
+//
+// gets StorageProviders = []any{NewDB, NewRepo}, registered by
+// di.Provide(c, StorageProviders...). Sets included from the same package
+// are expanded, the ones of other packages are referred to by their
+// generated slices, so diwire has to be run there as well. wire.Bind,
+// wire.Struct, wire.Value, wire.InterfaceValue and wire.FieldsOf have no
+// providers to call, they're reported and have to be replaced by di.Set.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const wirePath = "github.com/google/wire"
+
+type (
+	// set declared by wire.NewSet
+	set struct {
+		name string
+		args []ast.Expr
+		file *ast.File
+	}
+
+	generator struct {
+		fset *token.FileSet
+		info *types.Info
+		pkg  *types.Package
+		sets map[string]*set
+	}
+)
+
+func main() {
+	out := flag.String("out", "providers_gen.go", "output file")
+	flag.Parse()
+
+	src, err := generate(".")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "diwire:", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "diwire:", err)
+		os.Exit(1)
+	}
+}
+
+// generate source of provider slices of wire sets of package in dir
+func generate(dir string) ([]byte, error) {
+	g, err := load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(g.sets))
+	for name := range g.sets {
+		names = append(names, name)
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no wire.NewSet found in %s", g.pkg.Name())
+	}
+
+	slices.Sort(names)
+
+	var (
+		decls   bytes.Buffer
+		concat  bool
+		imports = map[string]string{}
+	)
+
+	for _, name := range names {
+		providers, included, err := g.expand(g.sets[name], map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, inc := range included {
+			imports[inc.path] = inc.name
+		}
+
+		value := "[]any{" + strings.Join(providers, ", ") + "}"
+		if len(included) > 0 {
+			concat = true
+			parts := []string{value}
+			for _, inc := range included {
+				parts = append(parts, inc.name+"."+providersName(inc.set))
+			}
+
+			value = "slices.Concat(" + strings.Join(parts, ", ") + ")"
+		}
+
+		fmt.Fprintf(&decls, "\n// %s are providers of %s\nvar %s = %s\n", providersName(name), name, providersName(name), value)
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by diwire; DO NOT EDIT.\n\npackage %s\n", g.pkg.Name())
+
+	if concat {
+		imports["slices"] = "slices"
+	}
+
+	if len(imports) > 0 {
+		paths := make([]string, 0, len(imports))
+		for p := range imports {
+			paths = append(paths, p)
+		}
+
+		slices.Sort(paths)
+
+		src.WriteString("\nimport (\n")
+		for _, p := range paths {
+			if imports[p] != path.Base(p) {
+				fmt.Fprintf(&src, "\t%s %q\n", imports[p], p)
+				continue
+			}
+
+			fmt.Fprintf(&src, "\t%q\n", p)
+		}
+		src.WriteString(")\n")
+	}
+
+	src.Write(decls.Bytes())
+
+	return format.Source(src.Bytes())
+}
+
+// load package in dir and its wire sets, package has to type-check except
+// of wire itself, which may be not importable
+func load(dir string) (*generator, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		fset: token.NewFileSet(),
+		info: &types.Info{Uses: map[*ast.Ident]types.Object{}},
+		sets: map[string]*set{},
+	}
+
+	files := make([]*ast.File, 0, len(bp.GoFiles))
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(g.fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, f)
+		g.collect(f)
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(g.fset, "source", nil),
+		// wire is needed by wire.Build only, errors of its uses are ignored
+		Error: func(error) {},
+	}
+	g.pkg, _ = conf.Check(bp.ImportPath, g.fset, files, g.info)
+
+	return g, nil
+}
+
+// collect package level wire.NewSet declarations of f
+func (g *generator) collect(f *ast.File) {
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, value := range vs.Values {
+				if call, ok := value.(*ast.CallExpr); ok && wireCall(f, call) == "NewSet" {
+					g.sets[vs.Names[i].Name] = &set{name: vs.Names[i].Name, args: call.Args, file: f}
+				}
+			}
+		}
+	}
+}
+
+// included set of other package
+type included struct {
+	name, path, set string
+}
+
+// expand providers of s, sets of the same package are expanded in place
+func (g *generator) expand(s *set, seen map[string]bool) ([]string, []included, error) {
+	if seen[s.name] {
+		return nil, nil, fmt.Errorf("%s includes itself", s.name)
+	}
+	seen[s.name] = true
+
+	var (
+		providers []string
+		includes  []included
+	)
+
+	for _, arg := range s.args {
+		pos := g.fset.Position(arg.Pos())
+
+		switch arg := arg.(type) {
+		case *ast.Ident:
+			if nested, ok := g.sets[arg.Name]; ok {
+				nestedProviders, nestedIncludes, err := g.expand(nested, seen)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				providers, includes = append(providers, nestedProviders...), append(includes, nestedIncludes...)
+				continue
+			}
+
+			providers = append(providers, arg.Name)
+			continue
+		case *ast.SelectorExpr:
+			x, _ := arg.X.(*ast.Ident)
+			pkg, ok := g.info.Uses[x].(*types.PkgName)
+			if ok && isProviderSet(g.info.Uses[arg.Sel]) {
+				includes = append(includes, included{name: pkg.Name(), path: pkg.Imported().Path(), set: arg.Sel.Name})
+				continue
+			}
+
+			if ok {
+				providers = append(providers, pkg.Name()+"."+arg.Sel.Name)
+				continue
+			}
+		case *ast.CallExpr:
+			if fn := wireCall(s.file, arg); fn != "" {
+				return nil, nil, fmt.Errorf("%s: wire.%s isn't supported, register it by di.Set", pos, fn)
+			}
+		}
+
+		return nil, nil, fmt.Errorf("%s: unsupported provider of %s", pos, s.name)
+	}
+
+	return providers, includes, nil
+}
+
+// wireCall returns name of function of wire package called, if it's one
+func wireCall(f *ast.File, call *ast.CallExpr) string {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+
+	x, ok := sel.X.(*ast.Ident)
+	if !ok || x.Name != importName(f, wirePath) {
+		return ""
+	}
+
+	return sel.Sel.Name
+}
+
+// importName of importPath in f, empty if it isn't imported
+func importName(f *ast.File, importPath string) string {
+	for _, spec := range f.Imports {
+		if p, _ := strconv.Unquote(spec.Path.Value); p != importPath {
+			continue
+		}
+
+		if spec.Name != nil {
+			return spec.Name.Name
+		}
+
+		return path.Base(importPath)
+	}
+
+	return ""
+}
+
+// isProviderSet reports whether obj is variable holding wire.ProviderSet
+func isProviderSet(obj types.Object) bool {
+	v, ok := obj.(*types.Var)
+	if !ok {
+		return false
+	}
+
+	named, ok := v.Type().(*types.Named)
+
+	return ok && named.Obj().Name() == "ProviderSet" && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == wirePath
+}
+
+// providersName of slice generated for set, e.g. StorageProviders for
+// StorageSet
+func providersName(setName string) string {
+	switch name := strings.TrimSuffix(setName, "Set"); {
+	case setName == "set":
+		return "providers"
+	case name == "":
+		return "Providers"
+	default:
+		return name + "Providers"
+	}
+}
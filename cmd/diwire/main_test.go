@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	src, err := generate(filepath.Join("testdata", "shop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := os.ReadFile(filepath.Join("testdata", "shop", "providers_gen.go.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(src) != string(expected) {
+		t.Errorf("Unexpected:\n%s", src)
+	}
+
+	if _, err := generate(filepath.Join("testdata", "bind")); err == nil || !strings.Contains(err.Error(), "wire.Bind isn't supported") {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
@@ -0,0 +1,12 @@
+package bind
+
+import (
+	"io"
+	"os"
+
+	"github.com/google/wire"
+)
+
+func NewFile() (*os.File, error) { return os.Stdin, nil }
+
+var Set = wire.NewSet(NewFile, wire.Bind(new(io.Reader), new(*os.File)))
@@ -0,0 +1,20 @@
+package shop
+
+import (
+	"database/sql"
+
+	"github.com/google/wire"
+)
+
+type Repo struct{ db *sql.DB }
+
+func NewDB() (*sql.DB, func(), error) { return nil, func() {}, nil }
+
+func NewRepo(db *sql.DB) *Repo { return &Repo{db: db} }
+
+func NewServer(*Repo) string { return "" }
+
+var (
+	StorageSet = wire.NewSet(NewDB, NewRepo)
+	AppSet     = wire.NewSet(StorageSet, NewServer)
+)
@@ -0,0 +1,103 @@
+// Package conn registers clients of external services, e.g. database or
+// Redis, which are pinged on setup and closed on cleanup. Client libraries
+// aren't imported, so the module stays free of dependencies, e.g. go-redis
+// one is wired as:
+//
+//	conn.Register(c, "", conn.Client[*redis.Client]{
+//		Open: func(c *di.Container) (*redis.Client, error) {
+//			client := redis.NewClient(di.Get[*redis.Options](c))
+//			return client, redisotel.InstrumentTracing(client)
+//		},
+//		Ping: func(ctx context.Context, client *redis.Client) error {
+//			return client.Ping(ctx).Err()
+//		},
+//		Close: (*redis.Client).Close,
+//	})
+package conn
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/irr123/di"
+)
+
+// Client describes how to open, check and close client of type T
+type Client[T any] struct {
+	// Open client, options could be got from container
+	Open func(*di.Container) (T, error)
+	// Ping checks client is usable, it's optional
+	Ping func(context.Context, T) error
+	// PingTimeout bounds Ping, default is 5 seconds
+	PingTimeout time.Duration
+	// Close client, it's optional
+	Close func(T) error
+}
+
+const defaultPingTimeout = 5 * time.Second
+
+// Register named client entity, it's pinged on setup and closed on cleanup.
+// Client which failed to ping is closed and setup fails.
+func Register[T any](c *di.Container, name string, client Client[T]) {
+	setup := di.OptSetupC(func(c *di.Container) (T, error) {
+		val, err := client.Open(c)
+		if err != nil {
+			return val, fmt.Errorf("open: %w", err)
+		}
+
+		if client.Ping == nil {
+			return val, nil
+		}
+
+		timeout := client.PingTimeout
+		if timeout <= 0 {
+			timeout = defaultPingTimeout
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		if err := client.Ping(ctx, val); err != nil {
+			err = fmt.Errorf("ping: %w", err)
+			if client.Close != nil {
+				err = errors.Join(err, client.Close(val))
+			}
+
+			return val, err
+		}
+
+		return val, nil
+	})
+
+	if client.Close == nil {
+		di.SetNamed(c, name, setup)
+		return
+	}
+
+	di.SetNamed(c, name, setup, di.OptCleanup(client.Close))
+}
+
+// SQL client of driver, dsn is got from container by dsn func, e.g.
+//
+//	conn.Register(c, "", conn.SQL("postgres", func(c *di.Container) (string, error) {
+//		return di.Get[Config](c).DSN, nil
+//	}))
+func SQL(driver string, dsn func(*di.Container) (string, error)) Client[*sql.DB] {
+	return Client[*sql.DB]{
+		Open: func(c *di.Container) (*sql.DB, error) {
+			source, err := dsn(c)
+			if err != nil {
+				return nil, err
+			}
+
+			return sql.Open(driver, source)
+		},
+		Ping: func(ctx context.Context, db *sql.DB) error {
+			return db.PingContext(ctx)
+		},
+		Close: (*sql.DB).Close,
+	}
+}
@@ -0,0 +1,79 @@
+package conn_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/irr123/di"
+	"github.com/irr123/di/conn"
+)
+
+type fakeClient struct {
+	addr   string
+	down   bool
+	closed bool
+}
+
+func fakeConn(down bool) conn.Client[*fakeClient] {
+	return conn.Client[*fakeClient]{
+		Open: func(c *di.Container) (*fakeClient, error) {
+			return &fakeClient{addr: di.GetNamed[string](c, "addr"), down: down}, nil
+		},
+		Ping: func(ctx context.Context, client *fakeClient) error {
+			if client.down {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+
+			return nil
+		},
+		PingTimeout: 10 * time.Millisecond,
+		Close: func(client *fakeClient) error {
+			client.closed = true
+			return nil
+		},
+	}
+}
+
+func TestRegister(t *testing.T) {
+	c := di.New()
+	di.SetNamed(c, "addr", di.OptSetup(func() (string, error) { return "localhost:6379", nil }))
+
+	conn.Register(c, "cache", fakeConn(false))
+
+	client := di.GetNamed[*fakeClient](c, "cache")
+	if client.addr != "localhost:6379" {
+		t.Errorf("Unexpected: %v", client.addr)
+	}
+
+	if err := c.Cleanup(); err != nil || !client.closed {
+		t.Errorf("Client should be closed: %v", err)
+	}
+}
+
+func TestRegisterPingFailed(t *testing.T) {
+	c := di.New()
+	di.SetNamed(c, "addr", di.OptSetup(func() (string, error) { return "", nil }))
+
+	conn.Register(c, "", fakeConn(true))
+
+	if _, err := di.TryGet[*fakeClient](c); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
+
+func TestSQL(t *testing.T) {
+	c := di.New()
+
+	errDSN := errors.New("no dsn")
+	conn.Register(c, "", conn.SQL("missing", func(*di.Container) (string, error) {
+		return "", errDSN
+	}))
+
+	if _, err := di.TryGet[*sql.DB](c); !errors.Is(err, errDSN) {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
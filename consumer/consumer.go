@@ -0,0 +1,110 @@
+// Package consumer runs message consumers managed by container, e.g. of Kafka
+// topic or AMQP queue. Consumer starts with Container.Init and its Cleanup
+// drains in-flight messages before the client they're received by is cleaned
+// up.
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/irr123/di"
+)
+
+type (
+	// Source receives messages, e.g. adapter of Kafka reader. Receive blocks
+	// until message arrives or ctx is done, it's called concurrently by
+	// workers.
+	Source[M any] interface {
+		Receive(ctx context.Context) (M, error)
+	}
+	// Handler of message, its error is logged, e.g. to let Source redeliver
+	// message handle acknowledgements in it
+	Handler[M any] func(context.Context, M) error
+	// Consumer receives messages from Source and handles them by workers
+	Consumer[M any] struct {
+		name    string
+		source  Source[M]
+		handler Handler[M]
+		stop    context.CancelFunc
+		wg      sync.WaitGroup
+	}
+)
+
+// retryDelay after failed Receive, so broken connection isn't hammered
+var retryDelay = time.Second
+
+// Register named *Consumer[M] entity, which receives messages from Source[M]
+// entity of the same name by workers. Handler is built by setup, so it gets
+// dependencies from container. Consumer is eager, Container.Init starts it,
+// Cleanup stops receiving and waits for in-flight messages, Source is cleaned
+// up after that as consumer depends on it.
+func Register[M any](c *di.Container, name string, workers int, setup func(*di.Container) (Handler[M], error)) {
+	di.SetNamed(c, name, di.OptSetupC(func(c *di.Container) (*Consumer[M], error) {
+		source, err := di.TryGetNamed[Source[M]](c, name)
+		if err != nil {
+			return nil, err
+		}
+
+		handler, err := setup(c)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, stop := context.WithCancel(context.Background())
+		consumer := &Consumer[M]{name: name, source: source, handler: handler, stop: stop}
+		for range max(workers, 1) {
+			consumer.wg.Add(1)
+			go consumer.work(ctx)
+		}
+
+		return consumer, nil
+	}), di.OptCleanup(func(consumer *Consumer[M]) error {
+		consumer.stop()
+		consumer.wg.Wait()
+
+		return nil
+	}), di.OptEager[*Consumer[M]]())
+}
+
+func (c *Consumer[M]) work(ctx context.Context) {
+	defer c.wg.Done()
+
+	for {
+		// message received just as consumer stops is handled still
+		msg, err := c.source.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			slog.Error("receive message", "consumer", c.name, "error", err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+
+			continue
+		}
+
+		// in-flight message is handled to the end regardless of stop
+		if err := c.handle(context.WithoutCancel(ctx), msg); err != nil {
+			slog.Error("handle message", "consumer", c.name, "error", err)
+		}
+	}
+}
+
+func (c *Consumer[M]) handle(ctx context.Context, msg M) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return c.handler(ctx, msg)
+}
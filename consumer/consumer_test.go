@@ -0,0 +1,130 @@
+package consumer_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/irr123/di"
+	"github.com/irr123/di/consumer"
+)
+
+type chanSource struct{ messages chan string }
+
+func (s chanSource) Receive(ctx context.Context) (string, error) {
+	select {
+	case msg := <-s.messages:
+		return msg, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func TestConsumer(t *testing.T) {
+	var (
+		c        = di.New()
+		mu       sync.Mutex
+		log      = []string{}
+		inFlight = make(chan struct{})
+		release  = make(chan struct{})
+		source   = chanSource{messages: make(chan string)}
+	)
+
+	di.SetNamed(c, "orders", di.OptSetup(func() (consumer.Source[string], error) {
+		return source, nil
+	}), di.OptCleanup(func(consumer.Source[string]) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		log = append(log, "close source")
+		return nil
+	}))
+	di.Set(c, di.OptSetup(func() (int, error) { return 42, nil }))
+
+	consumer.Register(c, "orders", 2, func(c *di.Container) (consumer.Handler[string], error) {
+		answer := di.Get[int](c)
+
+		return func(_ context.Context, msg string) error {
+			if msg == "slow" {
+				close(inFlight)
+				<-release
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			log = append(log, fmt.Sprint(msg, answer))
+			return nil
+		}, nil
+	})
+
+	if err := c.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	source.messages <- "slow"
+	<-inFlight
+
+	done := make(chan error)
+	go func() { done <- c.Cleanup() }()
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(log) != "[slow42 close source]" {
+		t.Errorf("Unexpected: %v", log)
+	}
+}
+
+// lateSource returns message received just as consumer stops
+type lateSource struct{ sent *sync.Once }
+
+func (s lateSource) Receive(ctx context.Context) (string, error) {
+	<-ctx.Done()
+
+	msg := ""
+	s.sent.Do(func() { msg = "late" })
+	if msg == "" {
+		return "", ctx.Err()
+	}
+
+	return msg, nil
+}
+
+func TestConsumerLateMessage(t *testing.T) {
+	var (
+		c       = di.New()
+		handled = make(chan string, 1)
+	)
+
+	di.SetNamed(c, "orders", di.OptSetup(func() (consumer.Source[string], error) {
+		return lateSource{sent: &sync.Once{}}, nil
+	}))
+
+	consumer.Register(c, "orders", 1, func(*di.Container) (consumer.Handler[string], error) {
+		return func(_ context.Context, msg string) error {
+			handled <- msg
+			return nil
+		}, nil
+	})
+
+	if err := c.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-handled:
+		if msg != "late" {
+			t.Errorf("Unexpected: %v", msg)
+		}
+	default:
+		t.Errorf("Message received on stop was dropped")
+	}
+}
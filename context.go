@@ -0,0 +1,51 @@
+package di
+
+import (
+	"context"
+	"fmt"
+)
+
+type ctxKey struct{}
+
+// ToContext returns copy of ctx carrying container
+func ToContext(ctx context.Context, c *Container) context.Context {
+	return context.WithValue(ctx, ctxKey{}, c)
+}
+
+// FromContext returns container stored by ToContext
+func FromContext(ctx context.Context) (*Container, bool) {
+	c, ok := ctx.Value(ctxKey{}).(*Container)
+	return c, ok
+}
+
+// OptFromContext entity value is extracted from context of scope it's
+// resolved in, see Container.BeginScopeContext
+func OptFromContext[T any](key any) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) {
+		s.scope = true
+		s.setupFn = func(c *Container) (T, func(), error) {
+			val, ok := c.ctx.Value(key).(T)
+			if !ok {
+				return empty[T](), nil, fmt.Errorf("context value not found: %v", key)
+			}
+
+			return val, nil, nil
+		}
+	}
+}
+
+// GetCtx entity from container stored in ctx. Attach root container once
+// and scopes on top of it, so the nearest one is used.
+func GetCtx[T any](ctx context.Context) T {
+	return GetNamedCtx[T](ctx, "")
+}
+
+// GetNamedCtx entity from container stored in ctx
+func GetNamedCtx[T any](ctx context.Context, name string) T {
+	c, ok := FromContext(ctx)
+	if !ok {
+		panic(resolveError{fmt.Errorf("container not found in context: %s", entityKey[T](name))})
+	}
+
+	return GetNamed[T](c, name)
+}
@@ -0,0 +1,83 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestContext(t *testing.T) {
+	c := di.New()
+
+	if _, ok := di.FromContext(context.Background()); ok {
+		t.Errorf("Empty context shouldn't carry container")
+	}
+
+	ctx := di.ToContext(context.Background(), c)
+	if got, ok := di.FromContext(ctx); !ok || got != c {
+		t.Errorf("Unexpected: %v, %v", got, ok)
+	}
+}
+
+func TestFromContextValues(t *testing.T) {
+	type (
+		requestIDKey struct{}
+		requestID    string
+		handler      string
+	)
+
+	c := di.New()
+	di.Set(c, di.OptFromContext[requestID](requestIDKey{}))
+
+	if _, err := di.TryGet[requestID](c); err == nil {
+		t.Errorf("Root container has no request context")
+	}
+
+	for _, id := range []requestID{"1", "2"} {
+		s := c.BeginScopeContext(context.WithValue(context.Background(), requestIDKey{}, id))
+		di.Set(s.Container, di.OptSetupC(func(c *di.Container) (handler, error) {
+			return handler("handler-" + di.Get[requestID](c)), nil
+		}))
+
+		if val := di.Get[handler](s.Container); val != handler("handler-"+id) {
+			t.Errorf("Unexpected: %v", val)
+		}
+
+		if ctx := di.Get[context.Context](s.Container); ctx.Value(requestIDKey{}) != id {
+			t.Errorf("Unexpected context: %v", ctx)
+		}
+
+		if err := s.End(); err != nil {
+			t.Errorf("Unexpected: %v", err)
+		}
+	}
+}
+
+func TestGetCtx(t *testing.T) {
+	c := di.New()
+	di.Set(c, di.OptSetup(func() (string, error) {
+		return "root", nil
+	}))
+
+	ctx := di.ToContext(context.Background(), c)
+	if val := di.GetCtx[string](ctx); val != "root" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	s := c.BeginScopeContext(ctx)
+	di.Set(s.Container, di.OptSetup(func() (string, error) {
+		return "scope", nil
+	}))
+
+	if val := di.GetCtx[string](di.ToContext(ctx, s.Container)); val != "scope" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("GetCtx should panic without container")
+		}
+	}()
+	di.GetCtx[string](context.Background())
+}
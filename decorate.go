@@ -0,0 +1,75 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+type decorator struct {
+	iface reflect.Type
+	fn    func(any) (any, bool)
+}
+
+// DecorateAll wraps every entity registered by interface type implementing
+// interface I, e.g. to add tracing to every repository. It's applied after
+// entity middlewares, to entities built through c or its scopes, including
+// the ones registered later. Entities whose type wrapper isn't of, e.g.
+// concrete types or interfaces wider than I, are left as is, so are nil
+// values.
+func DecorateAll[I any](c *Container, f func(I) I) {
+	iface := reflect.TypeFor[I]()
+	if iface.Kind() != reflect.Interface {
+		c.fail(Entity{Type: iface}, fmt.Errorf("decorate: %v is not an interface", iface))
+	}
+
+	c.decorators = append(c.decorators, decorator{
+		iface: iface,
+		fn: func(val any) (any, bool) {
+			v, ok := val.(I)
+			if !ok {
+				return nil, false
+			}
+
+			return f(v), true
+		},
+	})
+}
+
+// decorate val of type typ by decorators of c and its parents, the root ones
+// first
+func decorate[T any](c *Container, typ reflect.Type, val T) (T, error) {
+	if typ.Kind() != reflect.Interface {
+		return val, nil
+	}
+
+	chain := []*Container{}
+	for owner := c; owner != nil; owner = owner.parent {
+		chain = append(chain, owner)
+	}
+	slices.Reverse(chain)
+
+	for _, owner := range chain {
+		for _, d := range owner.decorators {
+			if !typ.Implements(d.iface) {
+				continue
+			}
+
+			result, ok := d.fn(val)
+			if !ok {
+				continue
+			}
+
+			if result == nil {
+				return empty[T](), fmt.Errorf("decorate: %v wrapped to nil", typ)
+			}
+
+			// wrapper of narrower interface doesn't fit
+			if decorated, ok := result.(T); ok && reflect.TypeOf(result).AssignableTo(typ) {
+				val = decorated
+			}
+		}
+	}
+
+	return val, nil
+}
@@ -0,0 +1,60 @@
+package di_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+type (
+	repository interface{ Find() string }
+	userRepo   struct{}
+	orderRepo  struct{}
+	tracedRepo struct{ next repository }
+)
+
+func (userRepo) Find() string     { return "user" }
+func (orderRepo) Find() string    { return "order" }
+func (r tracedRepo) Find() string { return "traced " + r.next.Find() }
+
+func TestDecorateAll(t *testing.T) {
+	c := di.New()
+
+	di.SetNamed(c, "user", di.OptSetup(func() (repository, error) {
+		return userRepo{}, nil
+	}))
+	di.DecorateAll(c, func(r repository) repository { return tracedRepo{next: r} })
+	di.SetNamed(c, "order", di.OptSetup(func() (repository, error) {
+		return orderRepo{}, nil
+	}))
+	di.Set(c, di.OptSetup(func() (userRepo, error) {
+		return userRepo{}, nil
+	}))
+
+	for name, expected := range map[string]string{"user": "traced user", "order": "traced order"} {
+		if val := di.GetNamed[repository](c, name).Find(); val != expected {
+			t.Errorf("Unexpected: %v", val)
+		}
+	}
+
+	if val, err := di.TryGet[userRepo](c); err != nil || val.Find() != "user" {
+		t.Errorf("Concrete type should be left as is: %v, %v", val, err)
+	}
+
+	di.Set(c, di.OptSetup(func() (io.ReadWriter, error) {
+		return &bytes.Buffer{}, nil
+	}))
+	di.DecorateAll(c, func(r io.Reader) io.Reader { return io.LimitReader(r, 1) })
+	if val, err := di.TryGet[io.ReadWriter](c); err != nil || val.(*bytes.Buffer) == nil {
+		t.Errorf("Wider interface should be left as is: %v, %v", val, err)
+	}
+
+	di.SetNamed(c, "nil", di.OptSetup(func() (repository, error) {
+		return nil, nil
+	}))
+	if val, err := di.TryGetNamed[repository](c, "nil"); err != nil || val != nil {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+}
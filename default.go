@@ -0,0 +1,33 @@
+package di
+
+import "sync/atomic"
+
+var defaultContainer atomic.Pointer[Container]
+
+func init() {
+	defaultContainer.Store(New())
+}
+
+// Default returns process-wide container, it's meant for small tools and
+// examples, libraries should accept *Container instead
+func Default() *Container {
+	return defaultContainer.Load()
+}
+
+// SwapDefault replaces default container and returns the previous one, so
+// tests could isolate themselves:
+//
+//	defer di.SwapDefault(di.SwapDefault(di.New()))
+func SwapDefault(c *Container) *Container {
+	return defaultContainer.Swap(c)
+}
+
+// SetDefault registers entity in default container, see Set
+func SetDefault[T any](opts ...func(*entityImpl[T])) Handle[T] {
+	return Set(Default(), opts...)
+}
+
+// GetDefault entity from default container, see Get
+func GetDefault[T any]() T {
+	return Get[T](Default())
+}
@@ -0,0 +1,39 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestDefault(t *testing.T) {
+	c := di.New()
+	defer di.SwapDefault(di.SwapDefault(c))
+
+	di.SetDefault(di.OptSetup(func() (string, error) { return "default", nil }))
+
+	if val := di.GetDefault[string](); val != "default" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if val := di.Get[string](c); val != "default" {
+		t.Errorf("Unexpected: %v", val)
+	}
+}
+
+func TestSwapDefault(t *testing.T) {
+	previous := di.Default()
+
+	func() {
+		defer di.SwapDefault(di.SwapDefault(di.New()))
+		di.SetDefault(di.OptSetup(func() (int, error) { return 42, nil }))
+	}()
+
+	if di.Default() != previous {
+		t.Errorf("Default should be restored")
+	}
+
+	if _, err := di.TryGet[int](di.Default()); err == nil {
+		t.Errorf("Registration should stay in swapped container")
+	}
+}
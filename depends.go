@@ -0,0 +1,124 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Dependency references entity by type and name, see OptDependsOn.
+// Name isn't prefixed by namespace.
+type Dependency struct {
+	Name string
+	Type reflect.Type
+
+	key string
+}
+
+// Dep references entity of type T
+func Dep[T any](name string) Dependency {
+	return Dependency{
+		Name: name,
+		Type: reflect.TypeFor[T](),
+		key:  entityKey[T](name),
+	}
+}
+
+func (d Dependency) String() string { return d.key }
+
+// OptDependsOn declares dependencies which aren't resolved by setup itself,
+// e.g. migrations which must run before repository. They're built before
+// entity and so cleaned up after it.
+func OptDependsOn[T any](deps ...Dependency) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.dependsOn = append(s.dependsOn, deps...) }
+}
+
+// Validate checks that dependencies declared by OptDependsOn and parameters
+// of Provide constructors are registered and don't form a cycle
+func (c *Container) Validate() error {
+	var (
+		errs  = []error{}
+		keys  = []string{}
+		names = map[string]string{}
+		deps  = map[string][]string{}
+	)
+
+	for _, e := range c.Entities() {
+		keys = append(keys, e.key)
+		names[e.key] = e.String()
+		for _, dep := range e.Dependencies {
+			if entity, _ := c.lookup(dep.key); entity == nil && !c.resolvableDep(dep) {
+				errs = append(errs, fmt.Errorf("dependency of %s not found: %s", e.key, dep))
+			}
+			deps[e.key] = append(deps[e.key], dep.key)
+		}
+	}
+
+	if cycle := findCycle(keys, deps); cycle != nil {
+		for i, key := range cycle {
+			cycle[i] = names[key]
+		}
+		errs = append(errs, fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> ")))
+	}
+
+	return errors.Join(errs...)
+}
+
+// resolvableDep which isn't registered, e.g. interface auto-bound to entity
+func (c *Container) resolvableDep(dep Dependency) bool {
+	if c.notFoundProvider() != nil {
+		return true
+	}
+
+	root := &Container{state: c.state}
+
+	return root.resolvable(dep.Type, dep.Name)
+}
+
+// findCycle of deps reachable from nodes, the first node is repeated at the end
+func findCycle(nodes []string, deps map[string][]string) []string {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	var (
+		state = map[string]int{}
+		stack = []string{}
+		visit func(string) []string
+	)
+
+	visit = func(node string) []string {
+		switch state[node] {
+		case visiting:
+			i := slices.Index(stack, node)
+			return append(slices.Clone(stack[i:]), node)
+		case visited:
+			return nil
+		}
+
+		state[node] = visiting
+		stack = append(stack, node)
+
+		for _, dep := range deps[node] {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[node] = visited
+
+		return nil
+	}
+
+	for _, node := range nodes {
+		if cycle := visit(node); cycle != nil {
+			return cycle
+		}
+	}
+
+	return nil
+}
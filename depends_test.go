@@ -0,0 +1,72 @@
+package di_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestDependsOn(t *testing.T) {
+	type (
+		migrations struct{}
+		repo       struct{}
+	)
+
+	var (
+		c   = di.New()
+		log = []string{}
+	)
+
+	di.Set(c, di.OptSetup(func() (repo, error) {
+		log = append(log, "setup repo")
+		return repo{}, nil
+	}), di.OptCleanup(func(repo) error {
+		log = append(log, "cleanup repo")
+		return nil
+	}), di.OptDependsOn[repo](di.Dep[migrations]("")))
+
+	if err := c.Validate(); err == nil {
+		t.Errorf("Validate should report missing migrations")
+	}
+
+	di.Set(c, di.OptSetup(func() (migrations, error) {
+		log = append(log, "setup migrations")
+		return migrations{}, nil
+	}), di.OptCleanup(func(migrations) error {
+		log = append(log, "cleanup migrations")
+		return nil
+	}))
+
+	if err := c.Validate(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	di.Get[repo](c)
+
+	if err := c.Cleanup(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if fmt.Sprint(log) != "[setup migrations setup repo cleanup repo cleanup migrations]" {
+		t.Errorf("Unexpected: %v", log)
+	}
+}
+
+func TestValidateCycle(t *testing.T) {
+	type (
+		a struct{}
+		b struct{}
+	)
+
+	c := di.New()
+
+	di.Set(c, di.OptDependsOn[a](di.Dep[b]("")))
+	di.Set(c, di.OptDependsOn[b](di.Dep[a]("")))
+
+	err := c.Validate()
+	if err == nil || !strings.Contains(err.Error(), "dependency cycle: <di_test.a> -> <di_test.b> -> <di_test.a>") {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
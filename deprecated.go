@@ -0,0 +1,39 @@
+package di
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// OptDeprecated marks entity as deprecated, hint tells what to use instead,
+// e.g. "use repoV2". Its resolution is logged by slog.Default once per call
+// site of Get, so the remaining users could be found. Strict container
+// fails to resolve it instead.
+func OptDeprecated[T any](hint string) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.deprecated = hint }
+}
+
+// reportDeprecated entity resolution unless it's reported for the call site
+func (c *Container) reportDeprecated(entityName string, entity entity, hint string) error {
+	if c.strict {
+		return fmt.Errorf("deprecated %v: %s", entity.info(), hint)
+	}
+
+	site := callerSite()
+
+	c.mu.Lock()
+	_, reported := c.deprecations[entityName+" "+site]
+	if !reported {
+		if c.deprecations == nil {
+			c.deprecations = make(map[string]struct{})
+		}
+		c.deprecations[entityName+" "+site] = struct{}{}
+	}
+	c.mu.Unlock()
+
+	if !reported {
+		slog.Warn("deprecated entity resolved", "entity", entity.info(), "hint", hint, "site", site)
+	}
+
+	return nil
+}
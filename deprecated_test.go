@@ -0,0 +1,32 @@
+package di_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestDeprecated(t *testing.T) {
+	var (
+		c   = di.New()
+		buf = &bytes.Buffer{}
+	)
+
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(buf, nil)))
+
+	di.SetNamed(c, "repo", di.OptSetup(func() (int, error) {
+		return 1, nil
+	}), di.OptDeprecated[int]("use repoV2"))
+
+	for range 2 {
+		di.GetNamed[int](c, "repo")
+	}
+
+	if out := buf.String(); strings.Count(out, "use repoV2") != 1 || !strings.Contains(out, "deprecated_test.go") {
+		t.Errorf("Unexpected: %v", out)
+	}
+}
@@ -1,101 +1,706 @@
 package di
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"runtime"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type (
 	Container struct {
+		*state
+		// namespace prefixed to names of entities, see Container.Namespace
+		namespace string
+		// chain of entities being built, whose setup got the container
+		chain *resolution
+		// caller of Get run on background goroutine, see GetAsync
+		caller *runtime.Frame
+	}
+	// resolution is link of chain of entities built by single top-level
+	// Get, it's carried by container passed to setup, so nested Gets of
+	// concurrent resolutions don't mix
+	resolution struct {
+		// id of top-level Get, see OptPerResolution
+		id     uint64
+		entity entity
+		parent *resolution
+		// scope is container entity is built in
+		scope *Container
+	}
+	state struct {
+		// mu guards entities, cleanup, scopes, redirects and resolution
+		// tracking, it's never held while entity is built. Entities are
+		// built under locks of their own, see instance.
+		mu sync.RWMutex
+
 		entities map[string]entity
 		cleanup  []cleanup
-		errs     []error
+		errs     errorStore
+
+		parent *Container
+		scopes []*Container
+		ctx    context.Context
+
+		// redirects of entity keys, see Promote
+		redirects map[string]string
+
+		decorators   []decorator
+		phases       []string
+		initPhases   []string
+		cleanupHooks []func(CleanupEvent)
+		// cleanupErrHandler decides what to do with failed cleanup
+		cleanupErrHandler func(Entity, error) CleanupPolicy
+		getHooks          []func(GetEvent)
+		// interceptors wrap resolution of every entity, see Use
+		interceptors []func(Resolver) Resolver
+		// strict container fails on the first recorded error, see Strict
+		strict bool
+		failed error
+		// setup failures counted against errorBudget, see ErrorBudget
+		errorBudget int
+		failures    int
+		lastFailure error
+		// setups limits background builds, see MaxConcurrentSetups
+		setups chan struct{}
+		// slowSetup threshold and handler, see OnSlowSetup
+		slowSetup        time.Duration
+		slowSetupHandler func(Entity, time.Duration)
+		// watchdog timeout of isolated setups, see Watchdog
+		watchdog time.Duration
+		// sealed container rejects registrations, see Builder
+		sealed bool
+		// registering is called by SetNamed, see Builder
+		registering func(entityName string)
+		// notFound provides entities which aren't registered, see OnNotFound
+		notFound func(Entity) (any, bool)
+
+		// resolutions counts top-level Gets, used by root container only
+		resolutions atomic.Uint64
+		// dependency graph recorded during resolution, used by root only
+		dependents map[entity][]entity
+		// registered counts registrations, so they could be ordered, root only
+		registered uint64
+		// deprecated entities resolution sites already reported, root only
+		deprecations map[string]struct{}
+		// transitions counts Init and Reload in progress, see Ready
+		transitions int
 	}
 	entity interface {
-		setup() (cleanup, error)
+		// setup builds entity if needed, returns its value and cleanup
+		setup(c *Container, step resolution) (any, func() error, error)
+		// scoped entity gets own instance in each scope
+		scoped() bool
+		// transient entity instance belongs to the scope it's resolved in
+		transient() bool
+		clone() entity
+		info() Entity
+		shutdownOrder() (phase string, priority int)
+		deprecation() string
+		// restriction is allowlist of packages resolving entity
+		restriction() []string
+		// reset cached instance, so it's built again
+		reset()
+		// rebuild instance in place, the old one is kept if build fails
+		rebuild(c *Container, step resolution) (any, func() error, error)
+		reapply(*Container) error
+		// probe built instance by liveness or readiness checks
+		probe(ctx context.Context, readiness bool) error
+		// drain instance before cleanup, see OptDrain
+		drain(ctx context.Context, val any) error
+	}
+	cleanup struct {
+		fn func() error
+		// val is instance cleaned up, see Release
+		val      any
+		source   entity
+		entity   Entity
+		phase    string
+		priority int
 	}
-	cleanup func() error
 )
 
 func New() *Container {
-	return &Container{
-		entities: make(map[string]entity),
-		errs:     make([]error, 0),
-		cleanup:  make([]cleanup, 0),
-	}
+	return &Container{state: &state{ctx: context.Background()}}
 }
 
 // Cleanup will deinitialize entities in opposite order as it was setuped.
+// Scopes which are not ended yet are ended first. Each entity instance is
+// deinitialized once, so repeated call handles only the ones setuped since.
 func (c *Container) Cleanup() error {
-	for i := len(c.cleanup) - 1; i >= 0; i-- {
-		c.errs = append(c.errs, c.cleanup[i]())
+	return c.CleanupContext(context.Background())
+}
+
+// CleanupContext is Cleanup which drains entities by ctx first, see OptDrain,
+// and stops once ctx is done, returning CleanupHungError which tells whose
+// cleanup didn't return
+func (c *Container) CleanupContext(ctx context.Context) error {
+	c.mu.RLock()
+	scopes := slices.Clone(c.scopes)
+	c.mu.RUnlock()
+
+	for i := len(scopes) - 1; i >= 0; i-- {
+		c.record(CleanupError, Entity{}, scopes[i].CleanupContext(ctx))
+	}
+
+	if c.parent != nil {
+		c.parent.mu.Lock()
+		c.parent.scopes = slices.DeleteFunc(c.parent.scopes, func(s *Container) bool { return s.state == c.state })
+		c.parent.mu.Unlock()
+
+		c.mu.RLock()
+		entities := make([]entity, 0, len(c.entities))
+		for _, entity := range c.entities {
+			entities = append(entities, entity)
+		}
+		c.mu.RUnlock()
+
+		c.root().forgetDependents(entities)
+	}
+
+	c.mu.Lock()
+	pending := c.cleanup
+	c.cleanup = nil
+	c.mu.Unlock()
+
+	// drains go in order instances were built, while all of them exist
+	for _, cleanup := range pending {
+		if err := cleanup.source.drain(ctx, cleanup.val); err != nil {
+			c.record(CleanupError, cleanup.entity, fmt.Errorf("drain %v: %w", cleanup.entity, err))
+		}
+	}
+
+	order := c.cleanupOrder(pending)
+	for i, cleanup := range order {
+		started := time.Now()
+		abort, err, hung := c.runCleanupContext(ctx, cleanup)
+		if !abort && !hung {
+			c.record(CleanupError, cleanup.entity, err)
+			continue
+		}
+
+		remaining := make([]Entity, 0, len(order)-i-1)
+		for _, rest := range order[i+1:] {
+			remaining = append(remaining, rest.entity)
+		}
+
+		if hung {
+			c.record(CleanupError, cleanup.entity, &CleanupHungError{
+				Err:       err,
+				Entity:    cleanup.entity,
+				Running:   time.Since(started),
+				Remaining: remaining,
+			})
+		} else {
+			c.record(CleanupError, cleanup.entity, &CleanupAbortedError{Err: err, Remaining: remaining})
+		}
+
+		// the rest remains to be cleaned up by next Cleanup call
+		rest := order[i+1:]
+		slices.Reverse(rest)
+
+		c.mu.Lock()
+		c.cleanup = append(rest, c.cleanup...)
+		c.mu.Unlock()
+
+		return c.errs.join()
 	}
 
-	return errors.Join(c.errs...)
+	return c.errs.join()
 }
 
 type entityImpl[T any] struct {
-	setupFn   func() (T, error)
-	cleanupFn func(T) error
+	// setupFn returns release of the instance, if it has one, see
+	// OptSetupCleanup
+	setupFn     func(*Container) (T, func(), error)
+	middlewares []middleware[T]
+	cleanupFn   func(T) error
+	// cleanupMws wrap cleanupFn, the last added is the outermost
+	cleanupMws []func(func(T) error) func(T) error
+
+	// typ of entity registered by Provide, whose T is any
+	typ  reflect.Type
+	name string
+	site string
+	// seq is registration order
+	seq       uint64
+	labels    []string
+	dependsOn []Dependency
+	// params of Provide constructor, they're resolved by constructor call
+	// and checked by Validate
+	params    []Dependency
+	phase     string
+	priority  int
+	initPhase string
+	// deprecated is a hint what to use instead, see OptDeprecated
+	deprecated string
+	// restrictedTo packages allowed to resolve entity, see OptRestrictTo
+	restrictedTo []string
+
+	// liveness and readiness checks, see OptLiveness and OptReadiness
+	liveness  []func(context.Context, T) error
+	readiness []func(context.Context, T) error
+	drains    []func(context.Context, T) error
+	// beforeGet and afterGet hooks, see OptBeforeGet and OptAfterGet
+	beforeGet []func() error
+	afterGet  []func(T) error
+
+	proxy         func(func() T) T
+	autoBind      bool
+	eager         bool
+	reloadable    bool
+	noReuse       bool
+	perResolution bool
+	scope         bool
+	retrySetup    bool
+
+	inst *instance[T]
+}
+
+// instance is built value of entity, registration copied by clone gets
+// a fresh one
+type instance[T any] struct {
+	// mu is held while instance is built, so concurrent Gets of the same
+	// entity build it once and the ones of different entities don't wait
+	// for each other
+	mu         sync.Mutex
+	built      atomic.Bool
+	resolution uint64
+	val        T
+	// err of failed setup returned by later Gets, see OptRetrySetup
+	err error
+}
+
+// setup builds entity if needed, returns its value and cleanup if there is one.
+// Cleanup of built instance is returned even if afterGet hook fails. Setup is
+// run by c carrying step, so its nested Gets are told apart.
+func (e *entityImpl[T]) setup(c *Container, step resolution) (any, func() error, error) {
+	for _, hook := range e.beforeGet {
+		if err := hook(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	val, cleanupFn, err := e.setupInstance(c, step)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, hook := range e.afterGet {
+		if err := hook(valueOf[T](val)); err != nil {
+			return val, cleanupFn, err
+		}
+	}
+
+	return val, cleanupFn, nil
+}
+
+func (e *entityImpl[T]) setupInstance(c *Container, step resolution) (any, func() error, error) {
+	// entity being built up the chain would wait for itself forever
+	if cycle := step.cycle(); cycle != nil {
+		return nil, nil, fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	// transient instances aren't shared, so they're built concurrently and
+	// aren't kept
+	if e.noReuse && !e.perResolution && e.setupFn != nil {
+		val, release, err := e.build(c.within(step))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		e.inst.built.Store(true)
+
+		return val, e.destructor(val, release), nil
+	}
+
+	inst := e.inst
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if e.setupFn == nil || (inst.built.Load() && !e.noReuse) {
+		return inst.val, nil, nil
+	}
+
+	if inst.err != nil {
+		return nil, nil, replayedError{inst.err}
+	}
+
+	if e.proxy != nil && !e.noReuse {
+		inst.val = e.proxy(e.lazy(c))
+		inst.built.Store(true)
+		return inst.val, nil, nil
+	}
+
+	if inst.built.Load() && e.perResolution && inst.resolution == step.id {
+		return inst.val, nil, nil
+	}
+
+	val, release, err := e.build(c.within(step))
+	if err != nil {
+		if !e.retrySetup && !e.noReuse && !e.perResolution {
+			inst.err = err
+		}
+
+		return nil, nil, err
+	}
+
+	inst.val, inst.resolution = val, step.id
+	inst.built.Store(true)
+
+	return val, e.destructor(val, release), nil
+}
+
+// destructor of val wrapped by cleanup middlewares, nil if there is nothing
+// to clean up
+func (e *entityImpl[T]) destructor(val T, release func()) func() error {
+	if e.cleanupFn == nil && len(e.cleanupMws) == 0 {
+		switch {
+		case release != nil:
+			return func() error { release(); return nil }
+		case len(e.drains) > 0:
+			// instance to drain is tracked by its pending cleanup
+			return func() error { return nil }
+		}
 
-	noReuse bool
-	val     T
+		return nil
+	}
+
+	cleanupFn := e.cleanupFn
+	if cleanupFn == nil {
+		cleanupFn = func(T) error { return nil }
+	}
+
+	for _, mw := range e.cleanupMws {
+		cleanupFn = mw(cleanupFn)
+	}
+
+	if release == nil {
+		return func() error { return cleanupFn(val) }
+	}
+
+	return func() error {
+		defer release()
+		return cleanupFn(val)
+	}
 }
 
-func (e *entityImpl[T]) setup() (cleanup, error) {
-	cleanup := func() error { return nil }
+// build runs setup and middlewares in ascending priority, the ones of equal
+// priority in order they were added. Middlewares added by OptMiddlewareOnce
+// run for the first built instance only. Release returned by setup is called
+// if middlewares fail.
+func (e *entityImpl[T]) build(c *Container) (_ T, release func(), _ error) {
+	for _, dep := range e.dependsOn {
+		if _, err := c.resolve(dep.key); err != nil {
+			return empty[T](), nil, err
+		}
+	}
+
+	var (
+		val T
+		err error
+	)
 
-	if e.setupFn == nil {
-		return cleanup, nil
+	// profiles taken during startup attribute setup cost to entity
+	labels := pprof.Labels("di.entity", Entity{Name: e.name, Type: e.entityType()}.String())
+	setup := func() (val T, err error) {
+		pprof.Do(c.ctx, labels, func(context.Context) { val, release, err = e.setupFn(c) })
+		return val, err
 	}
 
-	val, err := e.setupFn()
+	started := time.Now()
+	if timeout := c.watchdogTimeout(); timeout > 0 {
+		val, err = isolate(timeout, setup)
+	} else {
+		val, err = setup()
+	}
+	c.reportSlowSetup(e, time.Since(started))
+
 	if err != nil {
-		return cleanup, err
+		return empty[T](), nil, err
+	}
+
+	if val, err = e.applyMiddlewares(c, val); err == nil {
+		val, err = decorate(c, e.entityType(), val)
 	}
 
-	e.val = val
+	if err != nil {
+		if release != nil {
+			release()
+		}
 
-	if !e.noReuse {
-		e.setupFn = nil
+		return empty[T](), nil, err
 	}
 
-	if e.cleanupFn != nil {
-		cleanup = func() error { return e.cleanupFn(val) }
+	return val, release, nil
+}
+
+func (e *entityImpl[T]) applyMiddlewares(c *Container, val T) (T, error) {
+	middlewares := slices.Clone(e.middlewares)
+	slices.SortStableFunc(middlewares, func(a, b middleware[T]) int { return a.priority - b.priority })
+
+	for i, m := range middlewares {
+		if m.once && e.inst.built.Load() {
+			continue
+		}
+
+		var err error
+		if val, err = m.fn(c, val); err != nil {
+			return empty[T](), fmt.Errorf("middleware %d of %d (%s): %w", i+1, len(middlewares), m.site, err)
+		}
 	}
 
-	return cleanup, nil
+	return val, nil
+}
+
+func (e *entityImpl[T]) rebuild(c *Container, step resolution) (any, func() error, error) {
+	e.inst.mu.Lock()
+	defer e.inst.mu.Unlock()
+
+	val, release, err := e.build(c.within(step))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e.inst.val, e.inst.resolution, e.inst.err = val, step.id, nil
+	e.inst.built.Store(true)
+
+	return val, e.destructor(val, release), nil
+}
+
+// reapply middlewares to built instance
+func (e *entityImpl[T]) reapply(c *Container) error {
+	e.inst.mu.Lock()
+	defer e.inst.mu.Unlock()
+
+	if !e.inst.built.Load() || !e.kept() {
+		return nil
+	}
+
+	val, err := e.applyMiddlewares(c, e.inst.val)
+	if err != nil {
+		return err
+	}
+
+	e.inst.val = val
+
+	return nil
+}
+
+func (e *entityImpl[T]) scoped() bool { return e.scope }
+
+func (e *entityImpl[T]) transient() bool { return e.noReuse }
+
+// kept instance is cached by entity, transient ones are handed out only
+func (e *entityImpl[T]) kept() bool { return !e.noReuse || e.perResolution }
+
+func (e *entityImpl[T]) clone() entity {
+	clone := *e
+	clone.labels, clone.middlewares = slices.Clip(e.labels), slices.Clip(e.middlewares)
+	clone.cleanupMws, clone.dependsOn = slices.Clip(e.cleanupMws), slices.Clip(e.dependsOn)
+	clone.params = slices.Clip(e.params)
+	clone.liveness, clone.readiness = slices.Clip(e.liveness), slices.Clip(e.readiness)
+	clone.drains, clone.restrictedTo = slices.Clip(e.drains), slices.Clip(e.restrictedTo)
+	clone.beforeGet, clone.afterGet = slices.Clip(e.beforeGet), slices.Clip(e.afterGet)
+	clone.inst = new(instance[T])
+
+	return &clone
+}
+
+func (e *entityImpl[T]) reset() {
+	e.inst.mu.Lock()
+	defer e.inst.mu.Unlock()
+
+	e.inst.built.Store(false)
+	e.inst.resolution, e.inst.val, e.inst.err = 0, empty[T](), nil
+}
+
+func (e *entityImpl[T]) probe(ctx context.Context, readiness bool) error {
+	checks := e.liveness
+	if readiness {
+		checks = e.readiness
+	}
+
+	if len(checks) == 0 || !e.kept() {
+		return nil
+	}
+
+	// instance being built isn't ready, but it's alive
+	if !e.inst.mu.TryLock() {
+		if readiness {
+			return fmt.Errorf("%v: not ready: being built", e.info())
+		}
+
+		return nil
+	}
+	built, val := e.inst.built.Load(), e.inst.val
+	e.inst.mu.Unlock()
+
+	if !built {
+		return nil
+	}
+
+	errs := []error{}
+	for _, check := range checks {
+		if err := check(ctx, val); err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", e.info(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (e *entityImpl[T]) drain(ctx context.Context, val any) error {
+	errs := []error{}
+	for _, drain := range e.drains {
+		errs = append(errs, drain(ctx, valueOf[T](val)))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (e *entityImpl[T]) shutdownOrder() (string, int) { return e.phase, e.priority }
+
+func (e *entityImpl[T]) deprecation() string { return e.deprecated }
+
+func (e *entityImpl[T]) restriction() []string { return e.restrictedTo }
+
+func (e *entityImpl[T]) entityType() reflect.Type {
+	if e.typ != nil {
+		return e.typ
+	}
+
+	return reflect.TypeFor[T]()
+}
+
+func (e *entityImpl[T]) info() Entity {
+	return Entity{
+		Name:         e.name,
+		Type:         e.entityType(),
+		Site:         e.site,
+		Eager:        e.eager,
+		AutoBind:     e.autoBind,
+		Transient:    e.noReuse,
+		Reloadable:   e.reloadable,
+		Deprecated:   e.deprecated,
+		seq:          e.seq,
+		initPhase:    e.initPhase,
+		Built:        e.inst.built.Load(),
+		Labels:       slices.Clone(e.labels),
+		Dependencies: slices.Concat(e.dependsOn, e.params),
+	}
 }
 
 func empty[T any]() (t T) { return }
 
-func genName[T any](name string) string {
-	entityName := fmt.Sprintf("%T", empty[T]())
-	if entityName == "<nil>" {
-		entityName = fmt.Sprintf("%T", new(T))
+// keys interns entity keys, so Get doesn't format them over and over. Types
+// are told apart by their strings, which may coincide for types of different
+// packages, byString guards against that.
+var keys = struct {
+	sync.RWMutex
+	m        map[keyOf]string
+	byString map[string]reflect.Type
+}{m: make(map[keyOf]string), byString: make(map[string]reflect.Type)}
+
+type keyOf struct {
+	typ  reflect.Type
+	name string
+}
+
+// entityKey of entity of type T with name
+func entityKey[T any](name string) string {
+	return keyFor(reflect.TypeFor[T](), name)
+}
+
+func keyFor(typ reflect.Type, name string) string {
+	k := keyOf{typ: typ, name: name}
+
+	keys.RLock()
+	key, ok := keys.m[k]
+	keys.RUnlock()
+
+	if ok {
+		return key
+	}
+
+	keys.Lock()
+	defer keys.Unlock()
+
+	typeName := typ.String()
+	for i := 2; keys.byString[typeName] != nil && keys.byString[typeName] != typ; i++ {
+		typeName = fmt.Sprintf("%s#%d", typ, i)
 	}
+	keys.byString[typeName] = typ
 
-	return fmt.Sprintf("%s<%s>", name, entityName)
+	key = fmt.Sprintf("%s<%s>", name, typeName)
+	keys.m[k] = key
+
+	return key
 }
 
-// Set entity into container
-func Set[T any](c *Container, opts ...func(*entityImpl[T])) {
-	SetNamed(c, "", opts...)
+// Set entity into container, returned handle allows to modify registration
+// later
+func Set[T any](c *Container, opts ...func(*entityImpl[T])) Handle[T] {
+	return SetNamed(c, "", opts...)
 }
 
-// SetNamed entity to manually resolve collisions
-func SetNamed[T any](c *Container, name string, opts ...func(*entityImpl[T])) {
-	entityName := genName[*entityImpl[T]](name)
-	entity, ok := c.entities[entityName].(*entityImpl[T])
-	if !ok {
-		entity = new(entityImpl[T])
+// SetNamed entity to manually resolve collisions.
+// Being called on scope it overrides parent's registration for the scope
+// only, starting from a copy of it.
+func SetNamed[T any](c *Container, name string, opts ...func(*entityImpl[T])) Handle[T] {
+	entityName := entityKey[T](c.named(name))
+	c.checkRegistration(entityName)
+
+	c.mu.RLock()
+	found := c.entities[entityName]
+	c.mu.RUnlock()
+
+	var entity *entityImpl[T]
+	if found != nil {
+		entity = retype[T](c, entityName, found)
+	} else {
+		entity = &entityImpl[T]{inst: new(instance[T]), seq: c.root().nextSeq()}
+		if inherited, _ := c.lookup(entityName); inherited != nil {
+			entity = typed[T](inherited.clone())
+		}
+		entity.site = callerSite()
 	}
 
+	entity.name = c.named(name)
 	for _, opt := range opts {
 		opt(entity)
 	}
 
-	c.entities[entityName] = entity
+	c.mu.Lock()
+	c.put(entityName, entity)
+	c.mu.Unlock()
+
+	return Handle[T]{c: c, key: entityName, entity: entity}
+}
+
+// SetIfAbsent registers entity unless it's registered already, e.g. by
+// library default wiring which shouldn't clobber application's registration.
+// Reports whether entity was registered.
+func SetIfAbsent[T any](c *Container, opts ...func(*entityImpl[T])) bool {
+	return SetNamedIfAbsent(c, "", opts...)
+}
+
+// SetNamedIfAbsent registers named entity unless it's registered already
+func SetNamedIfAbsent[T any](c *Container, name string, opts ...func(*entityImpl[T])) bool {
+	if registered[T](c, name) {
+		return false
+	}
+
+	SetNamed(c, name, opts...)
+
+	return true
 }
 
 // Get entity from container
@@ -105,29 +710,470 @@ func Get[T any](c *Container) T {
 
 // GetNamed enntity to manually resolve collisions
 func GetNamed[T any](c *Container, name string) T {
-	entityName := genName[*entityImpl[T]](name)
-	entity, ok := c.entities[entityName]
+	val, err := resolve[T](c, name)
+	if err != nil {
+		c.fail(entityOf[T](c, name), err)
+	}
+
+	return val
+}
+
+// TryGet entity from container, returns error instead of panic
+func TryGet[T any](c *Container) (T, error) {
+	return TryGetNamed[T](c, "")
+}
+
+// TryGetNamed entity from container, returns error instead of panic.
+// Failures of nested Get calls made by setup are returned as well.
+func TryGetNamed[T any](c *Container, name string) (val T, err error) {
+	defer recoverResolveError(&err)
+
+	return resolve[T](c, name)
+}
+
+// GetOrDefault entity from container, fallback is returned if it isn't
+// registered, e.g. for optional collaborator with sane default
+func GetOrDefault[T any](c *Container, fallback T) T {
+	return GetNamedOrDefault(c, "", fallback)
+}
+
+// GetNamedOrDefault named entity from container, fallback is returned if it
+// isn't registered
+func GetNamedOrDefault[T any](c *Container, name string, fallback T) T {
+	if !registered[T](c, name) {
+		return fallback
+	}
+
+	return GetNamed[T](c, name)
+}
+
+// GetNamedOrDefaultName named entity from container, the unnamed one is
+// returned if it isn't registered, e.g. replica falling back to primary
+func GetNamedOrDefaultName[T any](c *Container, name string) T {
+	if !registered[T](c, name) {
+		return Get[T](c)
+	}
+
+	return GetNamed[T](c, name)
+}
+
+// registered tells if entity of type T with name is visible from c
+func registered[T any](c *Container, name string) bool {
+	return c.isRegistered(entityKey[T](c.named(name)))
+}
+
+func (c *Container) isRegistered(entityName string) bool {
+	entity, _ := c.lookup(c.redirect(entityName))
+	return entity != nil
+}
+
+// Get2 entities of types A and B, e.g. in setup which needs several of them
+func Get2[A, B any](c *Container) (A, B) {
+	return Get[A](c), Get[B](c)
+}
+
+// Get3 entities of types A, B and C
+func Get3[A, B, C any](c *Container) (A, B, C) {
+	return Get[A](c), Get[B](c), Get[C](c)
+}
+
+// TryGet2 entities of types A and B, returns error of the first failed one
+func TryGet2[A, B any](c *Container) (a A, b B, err error) {
+	defer recoverResolveError(&err)
+
+	if a, err = resolve[A](c, ""); err != nil {
+		return empty[A](), empty[B](), err
+	}
+
+	if b, err = resolve[B](c, ""); err != nil {
+		return empty[A](), empty[B](), err
+	}
+
+	return a, b, nil
+}
+
+// TryGet3 entities of types A, B and C, returns error of the first failed one
+func TryGet3[A, B, C any](c *Container) (a A, b B, cc C, err error) {
+	defer recoverResolveError(&err)
+
+	if a, b, err = TryGet2[A, B](c); err != nil {
+		return empty[A](), empty[B](), empty[C](), err
+	}
+
+	if cc, err = resolve[C](c, ""); err != nil {
+		return empty[A](), empty[B](), empty[C](), err
+	}
+
+	return a, b, cc, nil
+}
+
+// lookup entity through the scopes chain, returns container which owns it
+func (c *Container) lookup(entityName string) (entity, *Container) {
+	for owner := c; owner != nil; owner = owner.parent {
+		owner.mu.RLock()
+		entity, ok := owner.entities[entityName]
+		owner.mu.RUnlock()
+
+		if ok {
+			return entity, owner
+		}
+	}
+
+	return nil, nil
+}
+
+// adopt clone of scoped entity registered in parent, so it's built once
+// per scope
+func (c *Container) adopt(entityName string, inherited entity) entity {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entity, ok := c.entities[entityName]; ok {
+		return entity
+	}
+
+	entity := inherited.clone()
+	c.put(entityName, entity)
+
+	return entity
+}
+
+// put entity into c, which has to be locked. Map is allocated lazily, so
+// short-lived scopes allocate nothing until they need to.
+func (c *Container) put(entityName string, e entity) {
+	if c.entities == nil {
+		c.entities = make(map[string]entity)
+	}
+
+	c.entities[entityName] = e
+}
+
+func (c *Container) nextSeq() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.registered++
+
+	return c.registered
+}
+
+// step of resolution of entity through c, Get outside of setups starts
+// a new resolution
+func (c *Container) step(entity entity) resolution {
+	if c.chain == nil {
+		return resolution{id: c.root().resolutions.Add(1), entity: entity}
+	}
+
+	return resolution{id: c.chain.id, entity: entity, parent: c.chain}
+}
+
+// cycle of entities if entity of r is already being built up the chain
+func (r resolution) cycle() []string {
+	link := r.parent
+	for link != nil && link.entity != r.entity {
+		link = link.parent
+	}
+
+	if link == nil {
+		return nil
+	}
+
+	cycle := []string{r.entity.info().String()}
+	for link := r.parent; link.entity != r.entity; link = link.parent {
+		cycle = append(cycle, link.entity.info().String())
+	}
+
+	cycle = append(cycle, r.entity.info().String())
+	slices.Reverse(cycle)
+
+	return cycle
+}
+
+// within returns c carrying step, setup of step entity is run by it
+func (c *Container) within(step resolution) *Container {
+	step.scope = c
+	return &Container{state: c.state, namespace: c.namespace, chain: &step, caller: c.caller}
+}
+
+// recordDependency of entity whose setup runs in chain on dependency
+func (c *Container) recordDependency(chain *resolution, dependency entity) {
+	if chain == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.dependents == nil {
+		c.dependents = make(map[entity][]entity)
+	}
+
+	if dependent := chain.entity; !slices.Contains(c.dependents[dependency], dependent) {
+		c.dependents[dependency] = append(c.dependents[dependency], dependent)
+	}
+}
+
+// dependentsOf entity recorded during resolution
+func (c *Container) dependentsOf(dependency entity) []entity {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.Clone(c.dependents[dependency])
+}
+
+// forgetDependents of ended scope entities, so graph doesn't grow forever
+func (c *Container) forgetDependents(entities []entity) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.dependents) == 0 {
+		return
+	}
+
+	ended := func(e entity) bool { return slices.Contains(entities, e) }
+
+	for dependency, dependents := range c.dependents {
+		if ended(dependency) {
+			delete(c.dependents, dependency)
+			continue
+		}
+
+		c.dependents[dependency] = slices.DeleteFunc(dependents, ended)
+	}
+}
+
+func (c *Container) root() *Container {
+	for c.parent != nil {
+		c = c.parent
+	}
+
+	return c
+}
+
+// replayedError is remembered setup error returned by later Gets, it isn't
+// counted as failure again
+type replayedError struct{ error }
+
+func (e replayedError) Unwrap() error { return e.error }
+
+// resolveError is a panic value of failed Get, so TryGet could tell it
+// apart from panics of user code
+type resolveError struct{ error }
+
+func (e resolveError) Unwrap() error { return e.error }
+
+// fail records setup err and panics, so TryGet could recover it
+func (c *Container) fail(entity Entity, err error) {
+	c.record(SetupError, entity, err)
+	panic(resolveError{err})
+}
+
+// recoverResolveError turns panic of failed Get into err, has to be deferred
+func recoverResolveError(err *error) {
+	if r := recover(); r != nil {
+		rerr, ok := r.(resolveError)
+		if !ok {
+			panic(r)
+		}
+
+		*err = rerr.error
+	}
+}
+
+func resolve[T any](c *Container, name string) (T, error) {
+	val, err := c.resolveType(reflect.TypeFor[T](), name)
+	if err != nil {
+		return empty[T](), err
+	}
+
+	return valueOf[T](val), nil
+}
+
+// resolvable tells if entity could be resolved by resolveType without
+// asking OnNotFound provider
+func (c *Container) resolvable(typ reflect.Type, name string) bool {
+	if _, ok := c.scopeContext(typ, name); ok {
+		return true
+	}
+
+	if c.isRegistered(keyFor(typ, c.named(name))) {
+		return true
+	}
+
+	if typ.Kind() == reflect.Interface {
+		_, ok := c.autoBound(typ, c.named(name))
+		return ok
+	}
+
+	return false
+}
+
+// resolveType is resolution of entity by its type shared by Get and
+// reflective lookups of Provide, In and Inject
+func (c *Container) resolveType(typ reflect.Type, name string) (any, error) {
+	if ctx, ok := c.scopeContext(typ, name); ok {
+		return ctx, nil
+	}
+
+	entityName := keyFor(typ, c.named(name))
+	if typ.Kind() == reflect.Interface && !c.isRegistered(entityName) {
+		if bound, ok := c.autoBound(typ, c.named(name)); ok {
+			return c.resolveBound(typ, bound)
+		}
+	}
+
+	if c.notFoundProvider() != nil && !c.isRegistered(entityName) {
+		c.provideNotFound(typ, name)
+	}
+
+	return c.resolve(entityName)
+}
+
+// valueOf resolved entity, nil interface is returned as nil any
+func valueOf[T any](val any) T {
+	v, _ := val.(T)
+	return v
+}
+
+func (c *Container) resolve(entityName string) (any, error) {
+	return c.resolveBy(entityName, nil)
+}
+
+// resolveBy runs checks and hooks of resolution of entity, instantiate builds
+// it unless interceptors decide otherwise, nil one is c.instantiate, whose
+// method value would allocate on every Get
+func (c *Container) resolveBy(entityName string, instantiate func(string, entity, *Container) (any, error)) (any, error) {
+	entityName = c.redirect(entityName)
+
+	root := c.root()
+	if err := root.failure(); err != nil {
+		return nil, err
+	}
+
+	entity, owner := c.lookup(entityName)
+	if entity == nil {
+		return nil, fmt.Errorf("dependency not found: %s", entityName)
+	}
+
+	if hint := entity.deprecation(); hint != "" {
+		if err := root.reportDeprecated(entityName, entity, hint); err != nil {
+			return nil, err
+		}
+	}
+
+	if allowed := entity.restriction(); allowed != nil {
+		if err := checkRestriction(entity, allowed, c.caller); err != nil {
+			return nil, err
+		}
+	}
+
+	c.notifyGet(entity)
+
+	if c.intercepted() {
+		info := entity.info()
+		val, err := c.intercept(func(Entity) (any, error) {
+			if instantiate == nil {
+				return c.instantiate(entityName, entity, owner)
+			}
+
+			return instantiate(entityName, entity, owner)
+		})(info)
+		if err == nil && !assignable(val, info.Type) {
+			return nil, fmt.Errorf("interceptor returned %T for %v", val, info)
+		}
+
+		return val, err
+	}
+
+	if instantiate == nil {
+		return c.instantiate(entityName, entity, owner)
+	}
+
+	return instantiate(entityName, entity, owner)
+}
+
+// calledFrom returns c carrying caller frame of Get run on background
+// goroutine, whose stack has no caller
+func (c *Container) calledFrom(frame runtime.Frame, ok bool) *Container {
 	if !ok {
-		err := fmt.Errorf("dependency not found: %s", entityName)
-		c.errs = append(c.errs, err)
-		panic(err.Error())
+		return c
 	}
 
-	cleanup, err := entity.setup()
+	return &Container{state: c.state, namespace: c.namespace, chain: c.chain, caller: &frame}
+}
+
+// instantiate resolved entity in container it belongs to
+func (c *Container) instantiate(entityName string, entity entity, owner *Container) (any, error) {
+	switch {
+	case owner.state != c.state && entity.scoped():
+		entity, owner = c.adopt(entityName, entity), c
+	case entity.transient():
+		owner = c
+	}
+
+	root := c.root()
+	root.recordDependency(c.chain, entity)
+
+	val, cleanupFn, err := entity.setup(owner, c.step(entity))
+	owner.pushCleanup(entity, val, cleanupFn)
+
 	if err != nil {
-		err := fmt.Errorf("setup dependency %s: %w", entity, err)
-		c.errs = append(c.errs, err)
-		panic(err.Error())
+		_, replayed := err.(replayedError)
+		err = fmt.Errorf("setup dependency %s: %w", entityName, err)
+		if !replayed {
+			root.countFailure(err)
+		}
+
+		return nil, err
+	}
+
+	return val, nil
+}
+
+func (c *Container) pushCleanup(entity entity, val any, fn func() error) {
+	if fn == nil {
+		return
 	}
 
-	c.cleanup = append(c.cleanup, cleanup)
+	phase, priority := entity.shutdownOrder()
+	info := entity.info()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	return entity.(*entityImpl[T]).val
+	c.cleanup = append(c.cleanup, cleanup{
+		fn:       fn,
+		val:      val,
+		source:   entity,
+		entity:   info,
+		phase:    phase,
+		priority: priority,
+	})
 }
 
 // OptSetup entity "constructor"
 func OptSetup[T any](f func() (T, error)) func(*entityImpl[T]) {
-	return func(s *entityImpl[T]) { s.setupFn = f }
+	return OptSetupC(func(*Container) (T, error) { return f() })
+}
+
+// OptSetupC entity "constructor" which receives container it's resolved in,
+// so scoped entities could get dependencies of the same scope
+func OptSetupC[T any](f func(*Container) (T, error)) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) {
+		s.setupFn = func(c *Container) (T, func(), error) {
+			val, err := f(c)
+			return val, nil, err
+		}
+	}
+}
+
+// OptSetupCleanup entity "constructor" which returns cleanup of the instance
+// it built, as google/wire providers do. Cleanup runs after the one set by
+// OptCleanup, if any.
+func OptSetupCleanup[T any](f func() (T, func(), error)) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) {
+		s.setupFn = func(*Container) (T, func(), error) { return f() }
+	}
 }
 
 // OptNoReuse will recreate entity on each call
@@ -135,19 +1181,121 @@ func OptNoReuse[T any]() func(*entityImpl[T]) {
 	return func(s *entityImpl[T]) { s.noReuse = true }
 }
 
+// OptPerResolution will recreate entity on each top-level Get, but share
+// the instance between all nested Gets of the same resolution made through
+// container passed to setup, see OptSetupC.
+func OptPerResolution[T any]() func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.noReuse, s.perResolution = true, true }
+}
+
+// OptRetrySetup will run setup again on the next Get after failure, e.g. of
+// connection to service which might come up later. By default singleton
+// remembers setup error and returns it until Restart.
+func OptRetrySetup[T any]() func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.retrySetup = true }
+}
+
+type middleware[T any] struct {
+	priority int
+	once     bool
+	// site is file:line where middleware was added
+	site string
+	fn   func(*Container, T) (T, error)
+}
+
 // OptMiddleware allows to provide additional configuration
-// while entity already preserved in container
+// while entity already preserved in container. Middlewares run in order
+// they were added, after the ones added by OptMiddlewarePrepend.
+// Middleware runs for each built instance, so with OptNoReuse it gets
+// a fresh value from setup every time.
 func OptMiddleware[T any](f func(T) (T, error)) func(*entityImpl[T]) {
+	return OptMiddlewarePriority(0, f)
+}
+
+// OptMiddlewareC is OptMiddleware which receives container entity is resolved
+// in, so it could get additional dependencies (logger, metrics)
+func OptMiddlewareC[T any](f func(*Container, T) (T, error)) func(*entityImpl[T]) {
+	site := callerSite()
+
 	return func(s *entityImpl[T]) {
-		setupFn := s.setupFn
-		s.setupFn = func() (T, error) {
-			val, err := setupFn()
-			if err != nil {
-				return empty[T](), err
-			}
+		s.middlewares = append(s.middlewares, middleware[T]{site: site, fn: f})
+	}
+}
+
+// OptMiddlewarePrepend runs middleware before already added ones
+// of the same priority
+func OptMiddlewarePrepend[T any](f func(T) (T, error)) func(*entityImpl[T]) {
+	site := callerSite()
 
-			return f(val)
+	return func(s *entityImpl[T]) {
+		s.middlewares = slices.Insert(s.middlewares, 0, middleware[T]{
+			site: site,
+			fn:   func(_ *Container, val T) (T, error) { return f(val) },
+		})
+	}
+}
+
+// OptMiddlewareOnce is per entity configuration rather than per instance one:
+// with OptNoReuse it runs for the first built instance only, e.g. to register
+// type in a router or metrics collector
+func OptMiddlewareOnce[T any](f func(T) (T, error)) func(*entityImpl[T]) {
+	site := callerSite()
+
+	return func(s *entityImpl[T]) {
+		s.middlewares = append(s.middlewares, middleware[T]{
+			once: true,
+			site: site,
+			fn:   func(_ *Container, val T) (T, error) { return f(val) },
+		})
+	}
+}
+
+// OptMiddlewareIf runs middleware only if predicate, evaluated on each build,
+// returns true, e.g. to add debug logging in dev environment only
+func OptMiddlewareIf[T any](predicate func() bool, f func(T) (T, error)) func(*entityImpl[T]) {
+	return OptMiddleware(func(val T) (T, error) {
+		if !predicate() {
+			return val, nil
 		}
+
+		return f(val)
+	})
+}
+
+// Options bundles opts into single one, so common registration pattern could
+// be defined once and applied to many entities and containers, e.g.
+//
+//	pooled := di.Options(di.OptSetup(newPool), di.OptCleanup(closePool), di.OptLabels("db"))
+//	di.Set(c, pooled)
+func Options[T any](opts ...func(*entityImpl[T])) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) {
+		for _, opt := range opts {
+			opt(s)
+		}
+	}
+}
+
+// OptReplace drops options registration got so far, including inherited
+// from parent container, and its built instance, so registration starts over,
+// e.g. to override entity by fake in test. Pending cleanup of the dropped
+// instance still runs.
+func OptReplace[T any]() func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) {
+		*s = entityImpl[T]{name: s.name, site: s.site, seq: s.seq, inst: new(instance[T])}
+	}
+}
+
+// OptMiddlewarePriority runs middlewares in ascending priority regardless of
+// order they were added in, OptMiddleware has priority 0
+func OptMiddlewarePriority[T any](priority int, f func(T) (T, error)) func(*entityImpl[T]) {
+	site := callerSite()
+
+	return func(s *entityImpl[T]) {
+		s.middlewares = append(s.middlewares, middleware[T]{
+			priority: priority,
+			site:     site,
+			fn:       func(_ *Container, val T) (T, error) { return f(val) },
+		})
 	}
 }
 
@@ -155,3 +1303,17 @@ func OptMiddleware[T any](f func(T) (T, error)) func(*entityImpl[T]) {
 func OptCleanup[T any](f func(T) error) func(*entityImpl[T]) {
 	return func(s *entityImpl[T]) { s.cleanupFn = f }
 }
+
+// OptCleanupNoErr entity "destructor" which can't fail, e.g. stop of ticker
+func OptCleanupNoErr[T any](f func(T)) func(*entityImpl[T]) {
+	return OptCleanup(func(val T) error {
+		f(val)
+		return nil
+	})
+}
+
+// OptCleanupMiddleware wraps entity "destructor", e.g. to log or time it.
+// Middleware added later is called first.
+func OptCleanupMiddleware[T any](f func(next func(T) error) func(T) error) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.cleanupMws = append(s.cleanupMws, f) }
+}
@@ -1,10 +1,18 @@
 package di_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"runtime/pprof"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/irr123/di"
 )
@@ -236,7 +244,10 @@ func TestCleanup(t *testing.T) {
 		t.Errorf("Cleanup should return error")
 	}
 
-	if err.Error() != "3\n2\n1" {
+	expected := regexp.MustCompile(`^cleanup format<string> \(.+/di_test.go:\d+\): 3; ` +
+		`cleanup <string> \(.+/di_test.go:\d+\): 2; ` +
+		`cleanup <int> \(.+/di_test.go:\d+\): 1$`)
+	if !expected.MatchString(err.Error()) || !errors.Is(err, err1) {
 		t.Errorf("Unexpected: %v", err)
 	}
 }
@@ -268,7 +279,525 @@ func TestMultiCleanup(t *testing.T) {
 		t.Errorf("Cleanup should return error")
 	}
 
-	if err.Error() != "1" {
+	if !errors.Is(err, err1) || strings.Count(err.Error(), "\n") != 0 {
 		t.Errorf("Unexpected: %v", err)
 	}
 }
+
+func TestCleanupNoErr(t *testing.T) {
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (chan struct{}, error) {
+		return make(chan struct{}), nil
+	}), di.OptCleanupNoErr(func(ch chan struct{}) {
+		close(ch)
+	}))
+
+	ch := di.Get[chan struct{}](c)
+
+	if err := c.Cleanup(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if _, ok := <-ch; ok {
+		t.Errorf("Channel should be closed")
+	}
+}
+
+func TestTryGet(t *testing.T) {
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (string, error) {
+		return strconv.Itoa(di.Get[int](c)), nil
+	}))
+
+	if _, err := di.TryGet[int](c); err == nil {
+		t.Errorf("TryGet should return error")
+	}
+
+	if _, err := di.TryGet[string](c); err == nil {
+		t.Errorf("TryGet should return error of nested Get")
+	}
+
+	di.Set(c, di.OptSetup(func() (int, error) {
+		return 42, nil
+	}))
+
+	if val, err := di.TryGet[string](c); err != nil || val != "42" {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+}
+
+func TestSetIfAbsent(t *testing.T) {
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (string, error) { return "app", nil }))
+
+	if di.SetIfAbsent(c, di.OptSetup(func() (string, error) { return "lib", nil })) {
+		t.Errorf("Registration shouldn't be replaced")
+	}
+
+	if !di.SetNamedIfAbsent(c, "lib", di.OptSetup(func() (string, error) { return "lib", nil })) {
+		t.Errorf("Registration expected")
+	}
+
+	if app, lib := di.Get[string](c), di.GetNamed[string](c, "lib"); app != "app" || lib != "lib" {
+		t.Errorf("Unexpected: %v, %v", app, lib)
+	}
+}
+
+func TestGetOrDefault(t *testing.T) {
+	c := di.New()
+
+	if val := di.GetOrDefault(c, 42); val != 42 || len(c.Errors()) != 0 {
+		t.Errorf("Unexpected: %v, %v", val, c.Errors())
+	}
+
+	di.Set(c, di.OptSetup(func() (int, error) { return 1, nil }))
+	di.SetNamed(c, "replica", di.OptSetup(func() (string, error) { return "replica", nil }))
+
+	if val := di.GetOrDefault(c, 42); val != 1 {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if val := di.GetNamedOrDefault(c, "replica", "default"); val != "replica" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if val := di.GetNamedOrDefault(c, "primary", "default"); val != "default" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if val := di.GetNamedOrDefaultName[int](c, "replica"); val != 1 {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if val := di.GetNamedOrDefaultName[string](c, "replica"); val != "replica" {
+		t.Errorf("Unexpected: %v", val)
+	}
+}
+
+func TestGet2Get3(t *testing.T) {
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (int, error) { return 42, nil }))
+	di.Set(c, di.OptSetup(func() (string, error) { return "answer", nil }))
+
+	if i, s := di.Get2[int, string](c); i != 42 || s != "answer" {
+		t.Errorf("Unexpected: %v, %v", i, s)
+	}
+
+	if i, s, err := di.TryGet2[int, string](c); err != nil || i != 42 || s != "answer" {
+		t.Errorf("Unexpected: %v, %v, %v", i, s, err)
+	}
+
+	if i, s, f, err := di.TryGet3[int, string, float64](c); err == nil || i != 0 || s != "" || f != 0 {
+		t.Errorf("Unexpected: %v, %v, %v, %v", i, s, f, err)
+	}
+
+	di.Set(c, di.OptSetup(func() (float64, error) { return 4.2, nil }))
+
+	if i, s, f := di.Get3[int, string, float64](c); i != 42 || s != "answer" || f != 4.2 {
+		t.Errorf("Unexpected: %v, %v, %v", i, s, f)
+	}
+}
+
+func TestPerResolution(t *testing.T) {
+	type (
+		tx      int
+		service [2]tx
+	)
+
+	var (
+		c     = di.New()
+		count = 0
+	)
+
+	di.Set(c, di.OptSetup(func() (tx, error) {
+		count++
+		return tx(count), nil
+	}), di.OptPerResolution[tx]())
+	di.Set(c, di.OptSetupC(func(c *di.Container) (service, error) {
+		return service{di.Get[tx](c), di.Get[tx](c)}, nil
+	}), di.OptNoReuse[service]())
+
+	if val := di.Get[service](c); val != (service{1, 1}) {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if val := di.Get[service](c); val != (service{2, 2}) {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if val := di.Get[tx](c); val != 3 {
+		t.Errorf("Unexpected: %v", val)
+	}
+}
+
+func TestPerResolutionConcurrent(t *testing.T) {
+	type tx struct{ _ int }
+
+	var (
+		c  = di.New()
+		wg sync.WaitGroup
+		mu sync.Mutex
+		// concurrent resolutions must not share instance
+		got = map[*tx]bool{}
+	)
+
+	di.Set(c, di.OptSetup(func() (*tx, error) {
+		time.Sleep(time.Millisecond)
+		return &tx{}, nil
+	}), di.OptPerResolution[*tx]())
+
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			val := di.Get[*tx](c)
+			mu.Lock()
+			got[val] = true
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(got) != 4 {
+		t.Errorf("Unexpected: %v", got)
+	}
+}
+
+func TestNoReuseConcurrent(t *testing.T) {
+	var (
+		c       = di.New()
+		started sync.WaitGroup
+		done    = make(chan struct{})
+	)
+
+	started.Add(2)
+	di.Set(c, di.OptSetup(func() (int, error) {
+		// both builds have to run at once to get past
+		started.Done()
+		started.Wait()
+		return 1, nil
+	}), di.OptNoReuse[int]())
+
+	go func() {
+		defer close(done)
+		f := di.GetAsync[int](c)
+		di.Get[int](c)
+		_, _ = f.Wait(context.Background())
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Transient builds shouldn't wait for each other")
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	c := di.New()
+	appendFn := func(suffix string) func(string) (string, error) {
+		return func(s string) (string, error) { return s + suffix, nil }
+	}
+
+	di.Set(c, di.OptMiddleware(appendFn("1")))
+	di.Set(c, di.OptMiddlewarePriority(10, appendFn("last")))
+	di.Set(c, di.OptMiddleware(appendFn("2")))
+	di.Set(c, di.OptMiddlewarePriority(-10, appendFn("first")))
+	di.Set(c, di.OptMiddlewarePrepend(appendFn("0")))
+	di.Set(c, di.OptSetup(func() (string, error) {
+		return "setup-", nil
+	}))
+
+	if val := di.Get[string](c); val != "setup-first012last" {
+		t.Errorf("Unexpected: %v", val)
+	}
+}
+
+func TestMiddlewareIf(t *testing.T) {
+	var (
+		c   = di.New()
+		dev = false
+	)
+
+	di.Set(c, di.OptSetup(func() (string, error) {
+		return "client", nil
+	}), di.OptMiddlewareIf(func() bool { return dev }, func(s string) (string, error) {
+		return s + "+debug", nil
+	}), di.OptNoReuse[string]())
+
+	if val := di.Get[string](c); val != "client" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	dev = true
+	if val := di.Get[string](c); val != "client+debug" {
+		t.Errorf("Unexpected: %v", val)
+	}
+}
+
+func TestMiddlewareC(t *testing.T) {
+	type logger string
+
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (string, error) {
+		return "client", nil
+	}), di.OptMiddlewareC(func(c *di.Container, s string) (string, error) {
+		return s + "+" + string(di.Get[logger](c)), nil
+	}))
+	di.Set(c, di.OptSetup(func() (logger, error) {
+		return "logger", nil
+	}))
+
+	if val := di.Get[string](c); val != "client+logger" {
+		t.Errorf("Unexpected: %v", val)
+	}
+}
+
+func TestCleanupMiddleware(t *testing.T) {
+	var (
+		c   = di.New()
+		log = []string{}
+	)
+
+	logged := func(msg string) func(func(int) error) func(int) error {
+		return func(next func(int) error) func(int) error {
+			return func(i int) error {
+				log = append(log, msg+"-before")
+				err := next(i)
+				log = append(log, msg+"-after")
+				return err
+			}
+		}
+	}
+
+	di.Set(c, di.OptSetup(func() (int, error) {
+		return 42, nil
+	}), di.OptCleanupMiddleware(logged("inner")), di.OptCleanup(func(int) error {
+		log = append(log, "cleanup")
+		return nil
+	}), di.OptCleanupMiddleware(logged("outer")))
+
+	di.Get[int](c)
+
+	if err := c.Cleanup(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if fmt.Sprint(log) != "[outer-before inner-before cleanup inner-after outer-after]" {
+		t.Errorf("Unexpected: %v", log)
+	}
+}
+
+func TestMiddlewarePerInstance(t *testing.T) {
+	var (
+		c          = di.New()
+		count      = 0
+		registered = 0
+	)
+
+	di.Set(c, di.OptSetup(func() (*int, error) {
+		count++
+		val := count * 10
+		return &val, nil
+	}), di.OptMiddleware(func(i *int) (*int, error) {
+		*i++
+		return i, nil
+	}), di.OptMiddlewareOnce(func(i *int) (*int, error) {
+		registered++
+		return i, nil
+	}), di.OptNoReuse[*int]())
+
+	for i := 1; i < 5; i++ {
+		if val := di.Get[*int](c); *val != i*10+1 {
+			t.Errorf("Unexpected val: %v", *val)
+		}
+	}
+
+	if registered != 1 {
+		t.Errorf("Unexpected: %v", registered)
+	}
+}
+
+func TestSetupProfilerLabels(t *testing.T) {
+	c := di.New()
+
+	di.SetNamed(c, "db", di.OptSetup(func() (string, error) {
+		buf := &bytes.Buffer{}
+		if err := pprof.Lookup("goroutine").WriteTo(buf, 1); err != nil {
+			return "", err
+		}
+
+		return buf.String(), nil
+	}))
+
+	if profile := di.GetNamed[string](c, "db"); !strings.Contains(profile, `"di.entity":"db<string>"`) {
+		t.Errorf("Unexpected: %v", profile)
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	c := di.New()
+
+	di.SetNamed(c, "answer", di.OptSetup(func() (int, error) { return 42, nil }))
+
+	b.ReportAllocs()
+
+	for range b.N {
+		di.GetNamed[int](c, "answer")
+	}
+}
+
+func TestGetAllocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("race detector allocates")
+	}
+
+	c := di.New()
+
+	di.SetNamed(c, "answer", di.OptSetup(func() (int, error) { return 42, nil }))
+
+	if allocs := testing.AllocsPerRun(100, func() { di.GetNamed[int](c, "answer") }); allocs != 0 {
+		t.Errorf("Unexpected: %v", allocs)
+	}
+}
+
+func TestMiddlewareErrorPosition(t *testing.T) {
+	var (
+		c       = di.New()
+		errTest = errors.New("test")
+	)
+
+	di.Set(c,
+		di.OptSetup(func() (string, error) { return "", nil }),
+		di.OptMiddleware(func(s string) (string, error) { return s, nil }),
+		di.OptMiddleware(func(string) (string, error) { return "", errTest }),
+	)
+
+	_, err := di.TryGet[string](c)
+	if !errors.Is(err, errTest) {
+		t.Fatalf("Unexpected: %v", err)
+	}
+
+	if msg := err.Error(); !strings.Contains(msg, "middleware 2 of 2") || !strings.Contains(msg, "di_test.go:") {
+		t.Errorf("Unexpected: %v", msg)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	var (
+		cleaned []string
+		tagged  = func(tag string) func(string) (string, error) {
+			return func(s string) (string, error) { return s + tag, nil }
+		}
+		common = di.Options(
+			di.OptMiddleware(tagged("-traced")),
+			di.OptCleanup(func(s string) error {
+				cleaned = append(cleaned, s)
+				return nil
+			}),
+			di.OptLabels[string]("common"),
+		)
+	)
+
+	for _, name := range []string{"a", "b"} {
+		c := di.New()
+		di.Set(c, di.OptSetup(func() (string, error) { return name, nil }), common)
+
+		if val := di.Get[string](c); val != name+"-traced" {
+			t.Errorf("Unexpected: %v", val)
+		}
+
+		if entities := c.ByLabel("common"); len(entities) != 1 {
+			t.Errorf("Unexpected: %v", entities)
+		}
+
+		if err := c.Cleanup(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !slices.Equal(cleaned, []string{"a-traced", "b-traced"}) {
+		t.Errorf("Unexpected: %v", cleaned)
+	}
+}
+
+func TestOptSetupCleanup(t *testing.T) {
+	var (
+		c   = di.New()
+		log = []string{}
+	)
+
+	di.Set(c, di.OptSetupCleanup(func() (string, func(), error) {
+		return "conn", func() { log = append(log, "release") }, nil
+	}), di.OptCleanup(func(s string) error {
+		log = append(log, "cleanup "+s)
+		return nil
+	}))
+	di.Set(c, di.OptSetupCleanup(func() (int, func(), error) {
+		return 42, func() { log = append(log, "release int") }, nil
+	}), di.OptMiddleware(func(int) (int, error) { return 0, errors.New("test") }))
+
+	di.Get[string](c)
+	if _, err := di.TryGet[int](c); err == nil {
+		t.Errorf("TryGet should fail")
+	}
+
+	if err := c.Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(log) != "[release int cleanup conn release]" {
+		t.Errorf("Unexpected: %v", log)
+	}
+}
+
+func TestSetupErrorCached(t *testing.T) {
+	var (
+		c       = di.New()
+		errTest = errors.New("test")
+		calls   = map[string]int{}
+	)
+
+	for _, name := range []string{"cached", "retried"} {
+		di.SetNamed(c, name, di.OptSetup(func() (string, error) {
+			calls[name]++
+			if calls[name] == 1 {
+				return "", errTest
+			}
+
+			return name, nil
+		}))
+	}
+	di.SetNamed(c, "retried", di.OptRetrySetup[string]())
+
+	for range 2 {
+		if _, err := di.TryGetNamed[string](c, "cached"); !errors.Is(err, errTest) {
+			t.Errorf("Unexpected: %v", err)
+		}
+	}
+
+	if _, err := di.TryGetNamed[string](c, "retried"); !errors.Is(err, errTest) {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if val, err := di.TryGetNamed[string](c, "retried"); err != nil || val != "retried" {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+
+	if calls["cached"] != 1 || calls["retried"] != 2 {
+		t.Errorf("Unexpected: %v", calls)
+	}
+
+	if err := c.Restart(context.Background()); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if val, err := di.TryGetNamed[string](c, "cached"); err != nil || val != "cached" {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+}
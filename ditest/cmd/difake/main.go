@@ -0,0 +1,204 @@
+// Command difake generates zero-behavior fake of interface, which
+// ditest.Fakes provides when the interface isn't registered. Fake methods
+// return zero values. It's run by go generate in package of the interface:
+//
+//	//go:generate go run github.com/irr123/di/ditest/cmd/difake -type Repository
+//
+// Fake is written to fake_<type>_test.go by default and registered by
+// ditest.RegisterFake on init, so it's available to tests of the package.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const ditestPath = "github.com/irr123/di/ditest"
+
+func main() {
+	typeName := flag.String("type", "", "interface to fake")
+	out := flag.String("out", "", "output file, fake_<type>_test.go by default")
+	flag.Parse()
+
+	if *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *out == "" {
+		*out = "fake_" + strings.ToLower(*typeName) + "_test.go"
+	}
+
+	src, err := generate(".", *typeName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "difake:", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "difake:", err)
+		os.Exit(1)
+	}
+}
+
+// generate source of fake of interface typeName declared in package in dir
+func generate(dir, typeName string) ([]byte, error) {
+	iface, pkg, err := lookup(dir, typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	imports := map[string]string{ditestPath: "ditest"}
+	qualifier := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+
+		imports[p.Path()] = p.Name()
+
+		return p.Name()
+	}
+
+	fake := fakeName(typeName)
+
+	var methods bytes.Buffer
+	for i := range iface.NumMethods() {
+		m := iface.Method(i)
+		if !m.Exported() && m.Pkg() != pkg {
+			return nil, fmt.Errorf("%s: can't implement unexported method %s of %s", typeName, m.Name(), m.Pkg().Path())
+		}
+
+		sig := m.Type().(*types.Signature)
+		params := make([]string, sig.Params().Len())
+		for j := range params {
+			typ := sig.Params().At(j).Type()
+			if sig.Variadic() && j == len(params)-1 {
+				params[j] = "_ ..." + types.TypeString(typ.(*types.Slice).Elem(), qualifier)
+				continue
+			}
+
+			params[j] = "_ " + types.TypeString(typ, qualifier)
+		}
+
+		results := make([]string, sig.Results().Len())
+		for j := range results {
+			results[j] = fmt.Sprintf("r%d %s", j, types.TypeString(sig.Results().At(j).Type(), qualifier))
+		}
+
+		if len(results) == 0 {
+			fmt.Fprintf(&methods, "\nfunc (%s) %s(%s) {}\n", fake, m.Name(), strings.Join(params, ", "))
+			continue
+		}
+
+		fmt.Fprintf(&methods, "\nfunc (%s) %s(%s) (%s) { return }\n", fake, m.Name(), strings.Join(params, ", "), strings.Join(results, ", "))
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by difake; DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg.Name())
+
+	paths := make([]string, 0, len(imports))
+	for p := range imports {
+		paths = append(paths, p)
+	}
+
+	// standard library goes first, as goimports groups it
+	slices.SortFunc(paths, func(a, b string) int {
+		if std(a) != std(b) {
+			if std(a) {
+				return -1
+			}
+
+			return 1
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	for i, p := range paths {
+		if i > 0 && std(paths[i-1]) != std(p) {
+			src.WriteString("\n")
+		}
+
+		if imports[p] != path.Base(p) {
+			fmt.Fprintf(&src, "\t%s %q\n", imports[p], p)
+			continue
+		}
+
+		fmt.Fprintf(&src, "\t%q\n", p)
+	}
+
+	fmt.Fprintf(&src, ")\n\nfunc init() { ditest.RegisterFake[%s](%s{}) }\n\n", typeName, fake)
+	fmt.Fprintf(&src, "// %s is zero-behavior fake of %s\ntype %s struct{}\n", fake, typeName, fake)
+	src.Write(methods.Bytes())
+
+	return format.Source(src.Bytes())
+}
+
+// lookup interface typeName in package in dir
+func lookup(dir, typeName string) (*types.Interface, *types.Package, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(bp.GoFiles))
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		files = append(files, f)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check(bp.ImportPath, fset, files, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	obj, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s: type not found in %s", typeName, pkg.Name())
+	}
+
+	named, ok := obj.Type().(*types.Named)
+	if !ok || named.TypeParams().Len() > 0 {
+		return nil, nil, fmt.Errorf("%s: only non-generic named interfaces are supported", typeName)
+	}
+
+	iface, ok := named.Underlying().(*types.Interface)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s: not an interface", typeName)
+	}
+
+	return iface, pkg, nil
+}
+
+// fakeName of interface, it's exported if the interface is
+func fakeName(typeName string) string {
+	if ast.IsExported(typeName) {
+		return "Fake" + typeName
+	}
+
+	return "fake" + strings.ToUpper(typeName[:1]) + typeName[1:]
+}
+
+// std reports whether import path p is of standard library
+func std(p string) bool {
+	return !strings.Contains(strings.Split(p, "/")[0], ".")
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	src, err := generate(filepath.Join("testdata", "shop"), "Repository")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := os.ReadFile(filepath.Join("testdata", "shop", "fake_repository_test.go.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(src) != string(expected) {
+		t.Errorf("Unexpected:\n%s", src)
+	}
+
+	if _, err := generate(filepath.Join("testdata", "shop"), "Order"); err == nil {
+		t.Errorf("Error expected")
+	}
+}
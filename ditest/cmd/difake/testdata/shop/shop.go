@@ -0,0 +1,17 @@
+package shop
+
+import (
+	"context"
+	"io"
+)
+
+type (
+	Order struct{ ID string }
+
+	Repository interface {
+		io.Closer
+		Find(ctx context.Context, id string) (*Order, error)
+		Save(ctx context.Context, orders ...Order) error
+		Flush()
+	}
+)
@@ -0,0 +1,52 @@
+// Package ditest contains helpers for tests of application wiring
+package ditest
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/irr123/di"
+)
+
+// fakes of interfaces registered by RegisterFake
+var fakes sync.Map
+
+// Fakes makes c provide zero-behavior fakes of entities which aren't
+// registered, so unit tests wire only dependencies they assert on. Fake
+// function returns zero values, fake pointer points to zero value, fake of
+// interface is the one generated by difake command, see RegisterFake, the
+// rest of types are zero values. Interfaces without generated fakes remain
+// not found.
+func Fakes(c *di.Container) {
+	c.OnNotFound(func(e di.Entity) (any, bool) {
+		return fake(e.Type)
+	})
+}
+
+// RegisterFake of interface I provided by Fakes. It's called on init by code
+// generated by difake command, Go can't implement interface at runtime:
+//
+//	//go:generate go run github.com/irr123/di/ditest/cmd/difake -type Repository
+func RegisterFake[I any](fake I) {
+	fakes.Store(reflect.TypeFor[I](), fake)
+}
+
+func fake(typ reflect.Type) (any, bool) {
+	switch typ.Kind() {
+	case reflect.Interface:
+		return fakes.Load(typ)
+	case reflect.Func:
+		return reflect.MakeFunc(typ, func([]reflect.Value) []reflect.Value {
+			out := make([]reflect.Value, typ.NumOut())
+			for i := range out {
+				out[i] = reflect.Zero(typ.Out(i))
+			}
+
+			return out
+		}).Interface(), true
+	case reflect.Pointer:
+		return reflect.New(typ.Elem()).Interface(), true
+	default:
+		return reflect.Zero(typ).Interface(), true
+	}
+}
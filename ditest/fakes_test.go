@@ -0,0 +1,46 @@
+package ditest_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/irr123/di"
+	"github.com/irr123/di/ditest"
+)
+
+type (
+	notifier func(user string) error
+	config   struct{ Addr string }
+	mailer   interface{ Send(to string) error }
+
+	// fakeMailer as generated by difake
+	fakeMailer struct{}
+)
+
+func (fakeMailer) Send(_ string) (r0 error) { return }
+
+func TestFakes(t *testing.T) {
+	c := di.New()
+	ditest.Fakes(c)
+
+	if err := di.Get[notifier](c)("user"); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if cfg := di.Get[*config](c); cfg == nil || cfg.Addr != "" || cfg != di.Get[*config](c) {
+		t.Errorf("Unexpected: %v", cfg)
+	}
+
+	if val := di.GetNamed[int](c, "retries"); val != 0 {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	ditest.RegisterFake[mailer](fakeMailer{})
+	if err := di.Get[mailer](c).Send("user"); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if _, err := di.TryGet[io.Reader](c); err == nil {
+		t.Errorf("Error expected")
+	}
+}
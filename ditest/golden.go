@@ -0,0 +1,55 @@
+package ditest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+// UpdateEnv is environment variable which makes Golden write golden file
+// instead of comparing, e.g. DITEST_UPDATE=1 go test ./...
+const UpdateEnv = "DITEST_UPDATE"
+
+// Golden compares dependency graph of c with the one checked in golden file,
+// so unintended wiring changes fail tests. Resolve entities first, e.g. by
+// Init, see Container.Graph.
+func Golden(t testing.TB, c *di.Container, path string) {
+	t.Helper()
+
+	got := GraphString(c)
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("update golden file: %v", err)
+		}
+
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("update golden file: %v", err)
+		}
+
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file (set %s=1 to create it): %v", UpdateEnv, err)
+	}
+
+	if got != string(expected) {
+		t.Errorf("dependency graph differs from %s (set %s=1 to update it)\ngot:\n%s\nexpected:\n%s", path, UpdateEnv, got, expected)
+	}
+}
+
+// GraphString serializes dependency graph of c deterministically, an edge
+// per line
+func GraphString(c *di.Container) string {
+	b := strings.Builder{}
+	for _, edge := range c.Graph() {
+		fmt.Fprintf(&b, "%v -> %v\n", edge.From, edge.To)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package ditest_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/irr123/di"
+	"github.com/irr123/di/ditest"
+)
+
+type (
+	repo    struct{}
+	handler struct{ repo *repo }
+)
+
+func TestGolden(t *testing.T) {
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (*repo, error) { return &repo{}, nil }))
+	di.SetNamed(c, "handler", di.OptSetupC(func(c *di.Container) (*handler, error) {
+		return &handler{repo: di.Get[*repo](c)}, nil
+	}))
+
+	di.GetNamed[*handler](c, "handler")
+
+	ditest.Golden(t, c, filepath.Join("testdata", "graph.golden"))
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	var (
+		c    = di.New()
+		path = filepath.Join(t.TempDir(), "graph.golden")
+	)
+
+	t.Setenv(ditest.UpdateEnv, "1")
+	ditest.Golden(t, c, path)
+
+	if data, err := os.ReadFile(path); err != nil || len(data) != 0 {
+		t.Errorf("Unexpected: %q, %v", data, err)
+	}
+}
@@ -0,0 +1,51 @@
+package ditest
+
+import (
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+// Harness builds isolated container for each (sub)test from the same
+// modules, so table tests don't copy-paste wiring and are safe to run in
+// parallel
+type Harness struct {
+	modules []func(*di.Container)
+}
+
+// NewHarness of modules registering application entities
+func NewHarness(modules ...func(*di.Container)) *Harness {
+	return &Harness{modules: modules}
+}
+
+// Container built from modules with overrides of the test case applied on
+// top, it's cleaned up when test finishes
+func (h *Harness) Container(t testing.TB, overrides ...func(*di.Container)) *di.Container {
+	t.Helper()
+
+	c := di.New()
+	for _, module := range h.modules {
+		module(c)
+	}
+
+	for _, override := range overrides {
+		override(c)
+	}
+
+	t.Cleanup(func() {
+		if err := c.Cleanup(); err != nil {
+			t.Errorf("cleanup: %v", err)
+		}
+	})
+
+	return c
+}
+
+// Run f as subtest with its own container, see Container
+func (h *Harness) Run(t *testing.T, name string, f func(*testing.T, *di.Container), overrides ...func(*di.Container)) bool {
+	t.Helper()
+
+	return t.Run(name, func(t *testing.T) {
+		f(t, h.Container(t, overrides...))
+	})
+}
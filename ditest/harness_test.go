@@ -0,0 +1,39 @@
+package ditest_test
+
+import (
+	"testing"
+
+	"github.com/irr123/di"
+	"github.com/irr123/di/ditest"
+)
+
+func TestHarness(t *testing.T) {
+	type price int
+
+	h := ditest.NewHarness(func(c *di.Container) {
+		di.Set(c, di.OptSetup(func() (price, error) { return 100, nil }))
+	}, func(c *di.Container) {
+		di.SetNamed(c, "discounted", di.OptSetupC(func(c *di.Container) (price, error) {
+			return di.Get[price](c) * 9 / 10, nil
+		}))
+	})
+
+	for _, tc := range []struct {
+		name     string
+		override func(*di.Container)
+		expected price
+	}{
+		{name: "base", override: func(*di.Container) {}, expected: 90},
+		{name: "cheap", override: func(c *di.Container) {
+			di.Set(c, di.OptSetup(func() (price, error) { return 10, nil }))
+		}, expected: 9},
+	} {
+		h.Run(t, tc.name, func(t *testing.T, c *di.Container) {
+			t.Parallel()
+
+			if got := ditest.RequireGetNamed[price](t, c, "discounted"); got != tc.expected {
+				t.Errorf("Unexpected: %v", got)
+			}
+		}, tc.override)
+	}
+}
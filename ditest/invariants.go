@@ -0,0 +1,103 @@
+package ditest
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/irr123/di"
+)
+
+// CheckInvariants validates structure of c, e.g. within fuzz or property
+// tests of application wiring: dependency graph is acyclic, entities are
+// unique, pending cleanups belong to built entities and reused entities have
+// a cleanup pending at most once.
+func CheckInvariants(c *di.Container) error {
+	var (
+		errs     = []error{}
+		entities = map[string]di.Entity{}
+	)
+
+	for _, e := range c.Entities() {
+		if _, ok := entities[e.String()]; ok {
+			errs = append(errs, fmt.Errorf("duplicate entity %v", e))
+		}
+
+		entities[e.String()] = e
+	}
+
+	pending := map[string]int{}
+	for _, e := range c.Pending() {
+		pending[e.String()]++
+
+		switch registered, ok := entities[e.String()]; {
+		case !ok:
+			// entity of ended scope or removed one, nothing to compare with
+		case !registered.Built:
+			errs = append(errs, fmt.Errorf("cleanup of %v which isn't built", e))
+		case !registered.Transient && pending[e.String()] == 2:
+			errs = append(errs, fmt.Errorf("cleanup of %v is pending more than once", e))
+		}
+	}
+
+	if cycle := findCycle(c.Graph()); cycle != nil {
+		errs = append(errs, fmt.Errorf("dependency cycle %v", cycle))
+	}
+
+	return errors.Join(errs...)
+}
+
+// findCycle in graph, returns entities forming it
+func findCycle(edges []di.Edge) []string {
+	var (
+		deps  = map[string][]string{}
+		state = map[string]int{}
+		stack = []string{}
+		cycle []string
+		visit func(string) bool
+	)
+
+	for _, edge := range edges {
+		deps[edge.From.String()] = append(deps[edge.From.String()], edge.To.String())
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	visit = func(node string) bool {
+		switch state[node] {
+		case visiting:
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i] == node {
+					cycle = append(append([]string{}, stack[i:]...), node)
+					return true
+				}
+			}
+		case visited:
+			return false
+		}
+
+		state[node] = visiting
+		stack = append(stack, node)
+
+		for _, dep := range deps[node] {
+			if visit(dep) {
+				return true
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[node] = visited
+
+		return false
+	}
+
+	for _, edge := range edges {
+		if visit(edge.From.String()) {
+			return cycle
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package ditest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/irr123/di"
+	"github.com/irr123/di/ditest"
+)
+
+func TestCheckInvariants(t *testing.T) {
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (int, error) {
+		return 42, nil
+	}), di.OptCleanup(func(int) error { return nil }))
+	di.Set(c, di.OptSetup(func() (string, error) {
+		return "", nil
+	}), di.OptCleanup(func(string) error { return nil }), di.OptNoReuse[string]())
+	di.Set(c, di.OptSetupC(func(c *di.Container) (float64, error) {
+		return float64(di.Get[int](c)), nil
+	}), di.OptDependsOn[float64](di.Dep[string]("")))
+
+	for range 3 {
+		di.Get[float64](c)
+		di.Get[string](c)
+	}
+
+	if err := ditest.CheckInvariants(c); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if pending := c.Pending(); len(pending) != 5 {
+		t.Errorf("Unexpected: %v", pending)
+	}
+
+	di.Set(c, di.OptDependsOn[int](di.Dep[float64]("")))
+
+	if err := ditest.CheckInvariants(c); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
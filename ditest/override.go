@@ -0,0 +1,35 @@
+package ditest
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+// Override replaces registration of named entity of type T by val for the
+// test and fails the test if val is never handed out, which means the test
+// doesn't exercise code path it thinks it does
+func Override[T any](t testing.TB, c *di.Container, name string, val T) {
+	t.Helper()
+
+	var resolved atomic.Bool
+
+	_, file, line, _ := runtime.Caller(1)
+	entity := di.SetNamed(c, name,
+		di.OptReplace[T](),
+		di.OptSetup(func() (T, error) { return val, nil }),
+		di.OptAfterGet(func(T) error {
+			resolved.Store(true)
+			return nil
+		}),
+	).Entity()
+
+	t.Cleanup(func() {
+		if !resolved.Load() {
+			t.Errorf("override of %v at %s was never resolved", entity, fmt.Sprintf("%s:%d", file, line))
+		}
+	})
+}
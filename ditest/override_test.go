@@ -0,0 +1,84 @@
+package ditest_test
+
+import (
+	"testing"
+
+	"github.com/irr123/di"
+	"github.com/irr123/di/ditest"
+)
+
+type recordingT struct {
+	testing.TB
+	errors []string
+	clean  []func()
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, format)
+}
+
+func (r *recordingT) Cleanup(f func()) { r.clean = append(r.clean, f) }
+
+func (r *recordingT) finish() {
+	for i := len(r.clean) - 1; i >= 0; i-- {
+		r.clean[i]()
+	}
+}
+
+func TestOverride(t *testing.T) {
+	var (
+		c  = di.New()
+		rt = &recordingT{TB: t}
+	)
+
+	di.SetNamed(c, "primary", di.OptSetup(func() (store, error) { return "prod", nil }))
+	di.SetNamed(c, "replica", di.OptSetup(func() (store, error) { return "prod", nil }))
+
+	s := c.BeginScope()
+	ditest.Override[store](rt, s.Container, "primary", "fake")
+	ditest.Override[store](rt, s.Container, "replica", "fake")
+
+	if val := di.GetNamed[store](s.Container, "primary"); val != "fake" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	rt.finish()
+
+	if len(rt.errors) != 1 {
+		t.Errorf("Unexpected: %v", rt.errors)
+	}
+}
+
+func TestOverrideBuilt(t *testing.T) {
+	var (
+		c       = di.New()
+		rt      = &recordingT{TB: t}
+		cleaned = []store{}
+	)
+
+	di.Set(c, di.OptSetup(func() (store, error) {
+		return "prod", nil
+	}), di.OptMiddleware(func(s store) (store, error) {
+		return s + "+mw", nil
+	}), di.OptCleanup(func(s store) error {
+		cleaned = append(cleaned, s)
+		return nil
+	}))
+	di.Get[store](c)
+
+	ditest.Override[store](rt, c, "", "fake")
+	if val := di.Get[store](c); val != "fake" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if err := c.Cleanup(); err != nil || len(cleaned) != 1 || cleaned[0] != "prod+mw" {
+		t.Errorf("Unexpected: %v, %v", err, cleaned)
+	}
+
+	rt.finish()
+	if len(rt.errors) != 0 {
+		t.Errorf("Unexpected: %v", rt.errors)
+	}
+}
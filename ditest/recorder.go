@@ -0,0 +1,56 @@
+package ditest
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/irr123/di"
+)
+
+// Recorder records Gets of container, so tests could assert which entities
+// code path resolved, e.g. replica repository rather than primary one
+type Recorder struct {
+	mu   sync.Mutex
+	gets []di.GetEvent
+}
+
+// Record Gets of c and its scopes
+func Record(c *di.Container) *Recorder {
+	r := &Recorder{}
+	c.OnGet(func(e di.GetEvent) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.gets = append(r.gets, e)
+	})
+
+	return r
+}
+
+// Gets recorded so far in order they were made
+func (r *Recorder) Gets() []di.GetEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]di.GetEvent(nil), r.gets...)
+}
+
+// Reset drops recorded Gets
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.gets = nil
+}
+
+// Resolved returns Gets of entity of type T with name
+func Resolved[T any](r *Recorder, name string) []di.GetEvent {
+	result := []di.GetEvent{}
+	for _, e := range r.Gets() {
+		if e.Entity.Type == reflect.TypeFor[T]() && e.Entity.Name == name {
+			result = append(result, e)
+		}
+	}
+
+	return result
+}
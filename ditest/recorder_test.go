@@ -0,0 +1,38 @@
+package ditest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/irr123/di"
+	"github.com/irr123/di/ditest"
+)
+
+type store string
+
+func TestRecorder(t *testing.T) {
+	c := di.New()
+	r := ditest.Record(c)
+
+	for _, name := range []string{"primary", "replica"} {
+		di.SetNamed(c, name, di.OptSetup(func() (store, error) { return store(name), nil }))
+	}
+
+	read := func(c *di.Container) store { return di.GetNamed[store](c, "replica") }
+	read(c.BeginScope().Container)
+
+	if gets := ditest.Resolved[store](r, "primary"); len(gets) != 0 {
+		t.Errorf("Unexpected: %v", gets)
+	}
+
+	gets := ditest.Resolved[store](r, "replica")
+	if len(gets) != 1 || !strings.Contains(gets[0].Site, "recorder_test.go") {
+		t.Errorf("Unexpected: %v", gets)
+	}
+
+	r.Reset()
+
+	if gets := r.Gets(); len(gets) != 0 {
+		t.Errorf("Unexpected: %v", gets)
+	}
+}
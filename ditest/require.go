@@ -0,0 +1,29 @@
+package ditest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+// RequireGet entity from container, failure of resolution fails the test
+// with the entity and resolution trace instead of panic
+func RequireGet[T any](t testing.TB, c *di.Container) T {
+	t.Helper()
+
+	return RequireGetNamed[T](t, c, "")
+}
+
+// RequireGetNamed named entity from container, failure of resolution fails
+// the test
+func RequireGetNamed[T any](t testing.TB, c *di.Container, name string) T {
+	t.Helper()
+
+	val, err := di.TryGetNamed[T](c, name)
+	if err != nil {
+		t.Fatalf("get %s<%v>: %v", name, reflect.TypeFor[T](), err)
+	}
+
+	return val
+}
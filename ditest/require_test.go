@@ -0,0 +1,45 @@
+package ditest_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/irr123/di"
+	"github.com/irr123/di/ditest"
+)
+
+type fatalT struct {
+	testing.TB
+	fatal string
+}
+
+func (f *fatalT) Helper() {}
+
+func (f *fatalT) Fatalf(format string, args ...any) {
+	f.fatal = fmt.Sprintf(format, args...)
+}
+
+func TestRequireGet(t *testing.T) {
+	var (
+		c  = di.New()
+		ft = &fatalT{TB: t}
+	)
+
+	di.Set(c, di.OptSetup(func() (int, error) { return 0, errors.New("connection refused") }))
+	di.SetNamed(c, "handler", di.OptSetup(func() (string, error) {
+		return fmt.Sprint(di.Get[int](c)), nil
+	}))
+	di.Set(c, di.OptSetup(func() (float64, error) { return 4.2, nil }))
+
+	if val := ditest.RequireGet[float64](t, c); val != 4.2 {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	ditest.RequireGetNamed[string](ft, c, "handler")
+
+	if !strings.Contains(ft.fatal, "handler<string>") || !strings.Contains(ft.fatal, "connection refused") {
+		t.Errorf("Unexpected: %v", ft.fatal)
+	}
+}
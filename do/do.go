@@ -0,0 +1,79 @@
+// Package do is a thin adapter exposing github.com/samber/do like API
+// backed by di.Container, so projects can switch incrementally while
+// keeping their existing call sites.
+package do
+
+import "github.com/irr123/di"
+
+type (
+	// Injector wraps di.Container, the container itself stays reachable
+	// for mixing both APIs during migration
+	Injector struct {
+		*di.Container
+	}
+	// Provider is a service "constructor"
+	Provider[T any] func(*Injector) (T, error)
+	// Shutdownable services are shut down during Injector.Shutdown
+	Shutdownable interface {
+		Shutdown() error
+	}
+)
+
+func New() *Injector {
+	return &Injector{Container: di.New()}
+}
+
+// Shutdown will shut down services in opposite order as they were invoked
+func (i *Injector) Shutdown() error {
+	return i.Cleanup()
+}
+
+// Provide lazy service
+func Provide[T any](i *Injector, provider Provider[T]) {
+	ProvideNamed(i, "", provider)
+}
+
+// ProvideNamed lazy service
+func ProvideNamed[T any](i *Injector, name string, provider Provider[T]) {
+	di.SetNamed(i.Container, name, di.OptSetup(func() (T, error) {
+		return provider(i)
+	}), di.OptCleanup(shutdown[T]))
+}
+
+// ProvideValue already built service
+func ProvideValue[T any](i *Injector, value T) {
+	ProvideNamedValue(i, "", value)
+}
+
+// ProvideNamedValue already built service
+func ProvideNamedValue[T any](i *Injector, name string, value T) {
+	ProvideNamed(i, name, func(*Injector) (T, error) { return value, nil })
+}
+
+// Invoke service, building it if needed
+func Invoke[T any](i *Injector) (T, error) {
+	return di.TryGet[T](i.Container)
+}
+
+// InvokeNamed service, building it if needed
+func InvokeNamed[T any](i *Injector, name string) (T, error) {
+	return di.TryGetNamed[T](i.Container, name)
+}
+
+// MustInvoke service or panic
+func MustInvoke[T any](i *Injector) T {
+	return di.Get[T](i.Container)
+}
+
+// MustInvokeNamed service or panic
+func MustInvokeNamed[T any](i *Injector, name string) T {
+	return di.GetNamed[T](i.Container, name)
+}
+
+func shutdown[T any](val T) error {
+	if s, ok := any(val).(Shutdownable); ok {
+		return s.Shutdown()
+	}
+
+	return nil
+}
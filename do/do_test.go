@@ -0,0 +1,62 @@
+package do_test
+
+import (
+	"testing"
+
+	"github.com/irr123/di/do"
+)
+
+type (
+	config struct{ dsn string }
+	db     struct {
+		dsn    string
+		closed *bool
+	}
+)
+
+func (d db) Shutdown() error {
+	*d.closed = true
+	return nil
+}
+
+func TestProvideInvoke(t *testing.T) {
+	var (
+		i      = do.New()
+		closed = new(bool)
+	)
+
+	do.ProvideValue(i, config{dsn: "master"})
+	do.ProvideNamedValue(i, "replica", config{dsn: "replica"})
+	do.Provide(i, func(i *do.Injector) (db, error) {
+		return db{dsn: do.MustInvoke[config](i).dsn, closed: closed}, nil
+	})
+
+	if val := do.MustInvoke[db](i); val.dsn != "master" {
+		t.Errorf("Unexpected: %v", val.dsn)
+	}
+
+	val, err := do.InvokeNamed[config](i, "replica")
+	if err != nil || val.dsn != "replica" {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+
+	if err := i.Shutdown(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if !*closed {
+		t.Errorf("Shutdown should be called")
+	}
+}
+
+func TestInvokeMissing(t *testing.T) {
+	i := do.New()
+
+	do.Provide(i, func(i *do.Injector) (db, error) {
+		return db{dsn: do.MustInvoke[config](i).dsn}, nil
+	})
+
+	if _, err := do.Invoke[db](i); err == nil {
+		t.Errorf("Invoke should return error")
+	}
+}
@@ -0,0 +1,158 @@
+package di
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// Entity describes registration, it's returned by queries over container
+type Entity struct {
+	Name string
+	Type reflect.Type
+	// Site is file:line where entity was registered
+	Site         string
+	Labels       []string
+	Dependencies []Dependency
+	// Eager entity is built by Container.Init
+	Eager bool
+	// Transient entity is built on each Get, see OptNoReuse
+	Transient bool
+	// AutoBind entity is resolved by interfaces it implements, see
+	// OptAutoBindInterfaces
+	AutoBind bool
+	// Reloadable entity is rebuilt by Container.Reload
+	Reloadable bool
+	Built      bool
+	// Deprecated is a hint what to use instead, see OptDeprecated
+	Deprecated string
+
+	c         *Container
+	key       string
+	seq       uint64
+	initPhase string
+}
+
+func (e Entity) String() string {
+	return fmt.Sprintf("%s<%v>", e.Name, e.Type)
+}
+
+// entityOf describes entity of type T with name, which may be not registered
+func entityOf[T any](c *Container, name string) Entity {
+	return Entity{Name: c.named(name), Type: reflect.TypeFor[T]()}
+}
+
+// Get builds entity if needed and returns its value. Only entities returned
+// by queries, like Container.Entities, know their container, the ones passed
+// to hooks and recorded with errors return error.
+func (e Entity) Get() (val any, err error) {
+	defer recoverResolveError(&err)
+
+	if e.c == nil {
+		return nil, fmt.Errorf("entity %v isn't bound to container", e)
+	}
+
+	return e.c.resolve(e.key)
+}
+
+// Entities visible from container sorted by name and type
+func (c *Container) Entities() []Entity {
+	return c.query(func(Entity) bool { return true })
+}
+
+// Pending returns entities whose cleanups are pending in container, in order
+// they're cleaned up. Entity is listed once per its instance.
+func (c *Container) Pending() []Entity {
+	c.mu.RLock()
+	pending := slices.Clone(c.cleanup)
+	c.mu.RUnlock()
+
+	result := make([]Entity, 0, len(pending))
+	for _, cleanup := range c.cleanupOrder(pending) {
+		result = append(result, cleanup.entity)
+	}
+
+	return result
+}
+
+// query entities visible from container sorted by name and type, inner
+// scopes shadow outer
+func (c *Container) query(filter func(Entity) bool) []Entity {
+	seen := make(map[string]struct{})
+	result := make([]Entity, 0)
+
+	for owner := c; owner != nil; owner = owner.parent {
+		owner.mu.RLock()
+		entities := maps.Clone(owner.entities)
+		owner.mu.RUnlock()
+
+		for key, entity := range entities {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			info := entity.info()
+			info.c, info.key = c, key
+			if filter(info) {
+				result = append(result, info)
+			}
+		}
+	}
+
+	slices.SortFunc(result, func(a, b Entity) int {
+		if byName := strings.Compare(a.Name, b.Name); byName != 0 {
+			return byName
+		}
+
+		return strings.Compare(a.key, b.key)
+	})
+
+	return result
+}
+
+var (
+	pkgPrefix        = reflect.TypeFor[Container]().PkgPath() + "."
+	internalPrefixes = []string{pkgPrefix, "runtime.", "runtime/pprof.", "reflect."}
+)
+
+func internalFrame(function string) bool {
+	for _, prefix := range internalPrefixes {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// callerSite returns file:line of the first caller outside of this package
+func callerSite() string {
+	frame, ok := callerFrame()
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+}
+
+// callerFrame of the first caller outside of this package, frames of
+// runtime, e.g. of pprof.Do around setups or goroutine start, are skipped
+func callerFrame() (runtime.Frame, bool) {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+
+	for {
+		frame, more := frames.Next()
+		if !internalFrame(frame.Function) {
+			return frame, true
+		}
+
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package di_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestEntitiesPending(t *testing.T) {
+	c := di.New()
+
+	di.SetNamed(c, "b", di.OptSetup(func() (int, error) {
+		return 1, nil
+	}), di.OptCleanup(func(int) error { return nil }))
+	di.SetNamed(c, "a", di.OptSetup(func() (int, error) {
+		return 2, nil
+	}), di.OptCleanup(func(int) error { return nil }), di.OptNoReuse[int]())
+
+	if entities := fmt.Sprint(c.Entities()); entities != "[a<int> b<int>]" {
+		t.Errorf("Unexpected: %v", entities)
+	}
+
+	di.GetNamed[int](c, "b")
+	di.GetNamed[int](c, "a")
+	di.GetNamed[int](c, "a")
+
+	if pending := fmt.Sprint(c.Pending()); pending != "[a<int> a<int> b<int>]" {
+		t.Errorf("Unexpected: %v", pending)
+	}
+
+	if err := c.Cleanup(); err != nil || len(c.Pending()) != 0 {
+		t.Errorf("Unexpected: %v, %v", err, c.Pending())
+	}
+}
+
+func TestEntityGetUnbound(t *testing.T) {
+	c := di.New()
+
+	var events []di.CleanupEvent
+	c.OnCleanup(func(e di.CleanupEvent) { events = append(events, e) })
+
+	di.Set(c, di.OptSetup(func() (int, error) {
+		return 42, nil
+	}), di.OptCleanup(func(int) error { return nil }))
+	di.Get[int](c)
+
+	if val, err := c.Pending()[0].Get(); err == nil {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if err := c.Cleanup(); err != nil || len(events) != 2 {
+		t.Fatalf("Unexpected: %v, %v", err, events)
+	}
+
+	if val, err := events[0].Entity.Get(); err == nil {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if val, err := c.Entities()[0].Get(); err != nil || val != 42 {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+}
@@ -0,0 +1,177 @@
+package di
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type (
+	// ErrorKind categorizes errors recorded by container
+	ErrorKind int
+	// RecordedError is an error recorded by container with entity it relates
+	// to, if any
+	RecordedError struct {
+		Kind   ErrorKind
+		Entity Entity
+		Err    error
+	}
+	// Errors returned by Cleanup groups recorded errors by kind and entity.
+	// It renders single line by %v and Error, multi-line one grouped by kind
+	// and entity by %+v.
+	Errors []RecordedError
+	// errorStore is safe for concurrent use
+	errorStore struct {
+		mu      sync.Mutex
+		records []RecordedError
+	}
+)
+
+const (
+	// SetupError is recorded by failed Get
+	SetupError ErrorKind = iota + 1
+	// StartError is recorded by failed Init
+	StartError
+	// CleanupError is recorded by failed entity or scope cleanup
+	CleanupError
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case SetupError:
+		return "setup"
+	case StartError:
+		return "start"
+	case CleanupError:
+		return "cleanup"
+	default:
+		return fmt.Sprintf("ErrorKind(%d)", int(k))
+	}
+}
+
+func (e RecordedError) Error() string { return e.Err.Error() }
+
+func (e RecordedError) Unwrap() error { return e.Err }
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, record := range e {
+		msgs = append(msgs, record.Error())
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap makes errors.Is and errors.As look through every recorded error
+func (e Errors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, record := range e {
+		errs = append(errs, record)
+	}
+
+	return errs
+}
+
+func (e Errors) Format(f fmt.State, verb rune) {
+	if verb != 'v' || !f.Flag('+') {
+		fmt.Fprint(f, e.Error())
+		return
+	}
+
+	written := false
+	for _, kind := range []ErrorKind{SetupError, StartError, CleanupError} {
+		group := slices.DeleteFunc(slices.Clone(e), func(r RecordedError) bool { return r.Kind != kind })
+		if len(group) == 0 {
+			continue
+		}
+
+		if written {
+			fmt.Fprintln(f)
+		}
+		fmt.Fprintf(f, "%v errors:", kind)
+		written = true
+
+		for len(group) > 0 {
+			entity := group[0].Entity
+			switch {
+			case entity.Type == nil:
+				fmt.Fprint(f, "\n  container:")
+			case entity.Site == "":
+				fmt.Fprintf(f, "\n  %v:", entity)
+			default:
+				fmt.Fprintf(f, "\n  %v (%s):", entity, entity.Site)
+			}
+
+			for _, record := range group {
+				if record.Entity.Name == entity.Name && record.Entity.Type == entity.Type {
+					fmt.Fprintf(f, "\n    %v", record.Err)
+				}
+			}
+
+			group = slices.DeleteFunc(group, func(r RecordedError) bool {
+				return r.Entity.Name == entity.Name && r.Entity.Type == entity.Type
+			})
+		}
+	}
+}
+
+// Errors returns errors recorded so far, filtered by kinds if any given
+func (c *Container) Errors(kinds ...ErrorKind) Errors {
+	c.errs.mu.Lock()
+	defer c.errs.mu.Unlock()
+
+	result := make(Errors, 0, len(c.errs.records))
+	for _, record := range c.errs.records {
+		if len(kinds) == 0 || slices.Contains(kinds, record.Kind) {
+			result = append(result, record)
+		}
+	}
+
+	return result
+}
+
+// EntityErrors returns errors recorded so far which relate to entity
+// of type T with name
+func EntityErrors[T any](c *Container, name string) []RecordedError {
+	entity := entityOf[T](c, name)
+
+	return slices.DeleteFunc(c.Errors(), func(e RecordedError) bool {
+		return e.Entity.Name != entity.Name || e.Entity.Type != entity.Type
+	})
+}
+
+func (s *errorStore) add(kind ErrorKind, entity Entity, err error) {
+	if err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// errors of scope keep their own kinds and entities
+	if nested, ok := err.(Errors); ok {
+		s.records = append(s.records, nested...)
+		return
+	}
+
+	s.records = append(s.records, RecordedError{Kind: kind, Entity: entity, Err: err})
+}
+
+func (s *errorStore) join() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.records) == 0 {
+		return nil
+	}
+
+	return slices.Clone(Errors(s.records))
+}
+
+func (s *errorStore) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records = nil
+}
@@ -0,0 +1,100 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestErrors(t *testing.T) {
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (int, error) {
+		return 0, errors.New("setup")
+	}))
+	di.Set(c, di.OptSetup(func() (string, error) {
+		return "", nil
+	}), di.OptCleanup(func(string) error {
+		return errors.New("cleanup")
+	}))
+
+	di.Get[string](c)
+	if _, err := di.TryGet[float64](c); err == nil {
+		t.Errorf("TryGet should return error")
+	}
+
+	func() {
+		defer func() { _ = recover() }()
+		di.Get[int](c)
+	}()
+
+	if err := c.Cleanup(); err == nil {
+		t.Errorf("Cleanup should return error")
+	}
+
+	if errs := c.Errors(); len(errs) != 2 {
+		t.Errorf("Unexpected: %v", errs)
+	}
+
+	setup := c.Errors(di.SetupError)
+	if len(setup) != 1 || setup[0].Entity.Type.Name() != "int" {
+		t.Errorf("Unexpected: %v", setup)
+	}
+
+	cleanup := di.EntityErrors[string](c, "")
+	if len(cleanup) != 1 || cleanup[0].Kind != di.CleanupError {
+		t.Errorf("Unexpected: %v", cleanup)
+	}
+}
+
+func TestErrorsFormat(t *testing.T) {
+	var (
+		c       = di.New()
+		errTest = errors.New("test")
+	)
+
+	di.Set(c, di.OptSetup(func() (int, error) {
+		return 0, errTest
+	}), di.OptEager[int]())
+	di.SetNamed(c, "db", di.OptSetup(func() (string, error) {
+		return "db", nil
+	}), di.OptCleanup(func(string) error {
+		return errors.New("close")
+	}))
+
+	di.GetNamed[string](c, "db")
+	if err := c.Init(context.Background()); !errors.Is(err, errTest) {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	err := c.Cleanup()
+
+	var errs di.Errors
+	if !errors.As(err, &errs) || len(errs) != 2 || !errors.Is(err, errTest) {
+		t.Fatalf("Unexpected: %v", err)
+	}
+
+	if compact := fmt.Sprint(err); strings.Contains(compact, "\n") || compact != err.Error() {
+		t.Errorf("Unexpected: %q", compact)
+	}
+
+	verbose := regexp.MustCompile(`^start errors:
+  <int> \(.+/errors_test.go:\d+\):
+    .*test
+cleanup errors:
+  db<string> \(.+/errors_test.go:\d+\):
+    cleanup db<string> .*: close$`)
+	if got := fmt.Sprintf("%+v", err); !verbose.MatchString(got) {
+		t.Errorf("Unexpected:\n%s", got)
+	}
+
+	var record di.RecordedError
+	if !errors.As(err, &record) || record.Kind != di.StartError {
+		t.Errorf("Unexpected: %v", record)
+	}
+}
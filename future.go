@@ -0,0 +1,86 @@
+package di
+
+import "context"
+
+// Future is entity being built on background goroutine
+type Future[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// GetAsync starts building entity on background goroutine, e.g. to build
+// several expensive entities at startup concurrently and join on them.
+// Container builds each entity once however many goroutines get it.
+func GetAsync[T any](c *Container) *Future[T] {
+	return GetNamedAsync[T](c, "")
+}
+
+// GetNamedAsync starts building named entity on background goroutine
+func GetNamedAsync[T any](c *Container, name string) *Future[T] {
+	f := &Future[T]{done: make(chan struct{})}
+	c = c.calledFrom(callerFrame())
+
+	go func() {
+		defer close(f.done)
+		defer c.root().acquireSetup()()
+
+		f.val, f.err = TryGetNamed[T](c, name)
+	}()
+
+	return f
+}
+
+// Wait for entity like TryGet does, returns ctx error if ctx is done first.
+// Entity is built anyway then and may be got by another Wait or Get.
+func (f *Future[T]) Wait(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		return empty[T](), ctx.Err()
+	}
+}
+
+// Prefetch starts building entities on background goroutines while caller
+// proceeds, so the first request doesn't pay for lazy construction. Get of
+// entity being prefetched waits for it. Failures are recorded to container
+// as setup errors, the next Get returns the same error, unless entity is
+// registered with OptRetrySetup.
+func (c *Container) Prefetch(deps ...Dependency) {
+	c = c.calledFrom(callerFrame())
+	for _, dep := range deps {
+		go func() {
+			var err error
+			defer func() { c.record(SetupError, Entity{Name: dep.Name, Type: dep.Type}, err) }()
+			defer recoverResolveError(&err)
+			defer c.root().acquireSetup()()
+
+			_, err = c.resolve(dep.key)
+		}()
+	}
+}
+
+// MaxConcurrentSetups limits number of entities built on background
+// goroutines at once by GetAsync and Prefetch, e.g. so dozens of network
+// clients don't dial at once and trip rate limits of downstream. Nested Gets
+// of setup aren't limited, they're built on goroutine of the setup. Limit is
+// set before background builds start, n <= 0 removes it.
+func (c *Container) MaxConcurrentSetups(n int) {
+	c.setups = nil
+	if n > 0 {
+		c.setups = make(chan struct{}, n)
+	}
+}
+
+// acquireSetup slot, returns its release
+func (c *Container) acquireSetup() (release func()) {
+	setups := c.setups
+	if setups == nil {
+		return func() {}
+	}
+
+	setups <- struct{}{}
+
+	return func() { <-setups }
+}
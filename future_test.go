@@ -0,0 +1,189 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/irr123/di"
+)
+
+func TestGetAsync(t *testing.T) {
+	type (
+		db    struct{ id int }
+		cache struct{ db *db }
+	)
+
+	var (
+		c     = di.New()
+		built atomic.Int32
+		start = make(chan struct{})
+	)
+
+	di.Set(c, di.OptSetup(func() (*db, error) {
+		<-start
+		return &db{id: int(built.Add(1))}, nil
+	}))
+	di.Set(c, di.OptSetupC(func(c *di.Container) (*cache, error) {
+		return &cache{db: di.Get[*db](c)}, nil
+	}))
+
+	futures := []*di.Future[*cache]{di.GetAsync[*cache](c), di.GetAsync[*cache](c)}
+	dbFuture := di.GetAsync[*db](c)
+	close(start)
+
+	for _, f := range futures {
+		val, err := f.Wait(context.Background())
+		if err != nil || val.db.id != 1 {
+			t.Errorf("Unexpected: %v, %v", val, err)
+		}
+	}
+
+	if val, err := dbFuture.Wait(context.Background()); err != nil || val.id != 1 || built.Load() != 1 {
+		t.Errorf("Unexpected: %v, %v, %v", val, err, built.Load())
+	}
+}
+
+func TestGetAsyncError(t *testing.T) {
+	var (
+		c       = di.New()
+		errTest = errors.New("test")
+	)
+
+	di.Set(c, di.OptSetup(func() (int, error) { return 0, errTest }))
+
+	if _, err := di.GetAsync[int](c).Wait(context.Background()); !errors.Is(err, errTest) {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if _, err := di.GetNamedAsync[int](c, "missing").Wait(context.Background()); err == nil {
+		t.Errorf("Error expected")
+	}
+}
+
+func TestGetAsyncWaitCanceled(t *testing.T) {
+	var (
+		c       = di.New()
+		release = make(chan struct{})
+	)
+
+	di.Set(c, di.OptSetup(func() (int, error) {
+		<-release
+		return 42, nil
+	}))
+
+	f := di.GetAsync[int](c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := f.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	close(release)
+
+	if val, err := f.Wait(context.Background()); err != nil || val != 42 {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+}
+
+func TestPrefetch(t *testing.T) {
+	var (
+		c       = di.New()
+		built   atomic.Int32
+		errTest = errors.New("test")
+	)
+
+	di.Set(c, di.OptSetup(func() (int, error) {
+		time.Sleep(10 * time.Millisecond)
+		built.Add(1)
+		return 42, nil
+	}))
+	di.Set(c, di.OptSetup(func() (string, error) { return "", errTest }))
+
+	c.Prefetch(di.Dep[int](""), di.Dep[string](""))
+
+	if val := di.Get[int](c); val != 42 || built.Load() != 1 {
+		t.Errorf("Unexpected: %v, %v", val, built.Load())
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(c.Errors(di.SetupError)) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if errs := c.Errors(di.SetupError); len(errs) != 1 || !errors.Is(errs[0].Err, errTest) {
+		t.Errorf("Unexpected: %v", errs)
+	}
+}
+
+func TestMaxConcurrentSetups(t *testing.T) {
+	var (
+		c       = di.New()
+		running atomic.Int32
+		peak    atomic.Int32
+		deps    = []di.Dependency{}
+	)
+
+	c.MaxConcurrentSetups(2)
+
+	for i := range 10 {
+		name := strconv.Itoa(i)
+		di.SetNamed(c, name, di.OptSetup(func() (int, error) {
+			if n := running.Add(1); n > peak.Load() {
+				peak.Store(n)
+			}
+			defer running.Add(-1)
+
+			time.Sleep(5 * time.Millisecond)
+
+			return di.GetNamed[int](c, "leaf") + i, nil
+		}))
+		deps = append(deps, di.Dep[int](name))
+	}
+	di.SetNamed(c, "leaf", di.OptSetup(func() (int, error) { return 0, nil }))
+
+	c.Prefetch(deps...)
+
+	for i := range 10 {
+		if val, err := di.GetNamedAsync[int](c, strconv.Itoa(i)).Wait(context.Background()); err != nil || val != i {
+			t.Errorf("Unexpected: %v, %v", val, err)
+		}
+	}
+
+	if peak.Load() > 2 {
+		t.Errorf("Unexpected: %v", peak.Load())
+	}
+}
+
+func TestSlowSetupDoesNotBlockOthers(t *testing.T) {
+	var (
+		c       = di.New()
+		release = make(chan struct{})
+	)
+
+	di.SetNamed(c, "slow", di.OptSetup(func() (int, error) {
+		<-release
+		return 1, nil
+	}))
+	di.SetNamed(c, "fast", di.OptSetup(func() (int, error) { return 2, nil }))
+
+	slow := di.GetNamedAsync[int](c, "slow")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if val, err := di.GetNamedAsync[int](c, "fast").Wait(ctx); err != nil || val != 2 {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+
+	close(release)
+
+	if val, err := slow.Wait(context.Background()); err != nil || val != 1 {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+}
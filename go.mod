@@ -1,3 +1,5 @@
 module github.com/irr123/di
 
 go 1.22.0
+
+require github.com/google/wire v0.7.0 // indirect
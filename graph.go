@@ -0,0 +1,51 @@
+package di
+
+import (
+	"slices"
+	"strings"
+)
+
+// Edge of dependency graph, entity From depends on entity To
+type Edge struct {
+	From, To Entity
+}
+
+// Graph returns dependency edges recorded during resolution along with the
+// ones declared by OptDependsOn, sorted by names and types of entities.
+// Resolve entities first, e.g. by Init, to get the complete graph. Only Gets
+// through container passed to setup are recorded, see OptSetupC, the ones
+// through container captured by closure look like top-level ones.
+func (c *Container) Graph() []Edge {
+	edges := []Edge{}
+	for _, e := range c.Entities() {
+		for _, dep := range e.Dependencies {
+			to := Entity{Name: dep.Name, Type: dep.Type}
+			if entity, _ := c.lookup(dep.key); entity != nil {
+				to = entity.info()
+			}
+
+			edges = append(edges, Edge{From: e, To: to})
+		}
+	}
+
+	root := c.root()
+	root.mu.RLock()
+	for dependency, dependents := range root.dependents {
+		for _, dependent := range dependents {
+			edges = append(edges, Edge{From: dependent.info(), To: dependency.info()})
+		}
+	}
+	root.mu.RUnlock()
+
+	slices.SortFunc(edges, func(a, b Edge) int {
+		if byFrom := strings.Compare(a.From.String(), b.From.String()); byFrom != 0 {
+			return byFrom
+		}
+
+		return strings.Compare(a.To.String(), b.To.String())
+	})
+
+	return slices.CompactFunc(edges, func(a, b Edge) bool {
+		return a.From.String() == b.From.String() && a.To.String() == b.To.String()
+	})
+}
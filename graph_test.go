@@ -0,0 +1,42 @@
+package di_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestGraph(t *testing.T) {
+	type (
+		migrations struct{}
+		db         struct{}
+		repo       struct{ db *db }
+	)
+
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (*migrations, error) { return &migrations{}, nil }))
+	di.Set(c, di.OptSetup(func() (*db, error) { return &db{}, nil }))
+	di.SetNamed(c, "users", di.OptSetupC(func(c *di.Container) (*repo, error) {
+		return &repo{db: di.Get[*db](c)}, nil
+	}), di.OptDependsOn[*repo](di.Dep[*migrations]("")))
+
+	di.GetNamed[*repo](c, "users")
+	di.GetNamed[*repo](c.BeginScope().Container, "users")
+
+	edges := []string{}
+	for _, edge := range c.Graph() {
+		edges = append(edges, fmt.Sprintf("%v -> %v", edge.From, edge.To))
+	}
+
+	expected := []string{
+		"users<*di_test.repo> -> <*di_test.db>",
+		"users<*di_test.repo> -> <*di_test.migrations>",
+	}
+
+	if !slices.Equal(edges, expected) {
+		t.Errorf("Unexpected: %v", edges)
+	}
+}
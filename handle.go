@@ -0,0 +1,55 @@
+package di
+
+// Handle of entity registration returned by Set, so late configuration
+// doesn't have to call SetNamed again
+type Handle[T any] struct {
+	c      *Container
+	key    string
+	entity *entityImpl[T]
+}
+
+// Apply options to registration, the same as SetNamed with them does
+func (h Handle[T]) Apply(opts ...func(*entityImpl[T])) Handle[T] {
+	h.c.checkSealed()
+	for _, opt := range opts {
+		opt(h.entity)
+	}
+
+	return h
+}
+
+// AddMiddleware to registration, see OptMiddleware
+func (h Handle[T]) AddMiddleware(f func(T) (T, error)) Handle[T] {
+	return h.Apply(OptMiddleware(f))
+}
+
+// ReplaceSetup of registration, instance which is built already is kept
+func (h Handle[T]) ReplaceSetup(f func() (T, error)) Handle[T] {
+	return h.Apply(OptSetup(f))
+}
+
+// Remove registration from container, built instance is cleaned up.
+// Registration which replaced this one since is kept.
+func (h Handle[T]) Remove() error {
+	h.c.checkSealed()
+	h.c.mu.Lock()
+	registered := h.c.entities[h.key] == entity(h.entity)
+	if registered {
+		delete(h.c.entities, h.key)
+	}
+	h.c.mu.Unlock()
+
+	if !registered {
+		return nil
+	}
+
+	return h.c.resetEntity(h.entity)
+}
+
+// Entity describes registration
+func (h Handle[T]) Entity() Entity {
+	info := h.entity.info()
+	info.c, info.key = h.c, h.key
+
+	return info
+}
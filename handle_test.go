@@ -0,0 +1,61 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestHandle(t *testing.T) {
+	var (
+		c       = di.New()
+		cleaned = 0
+	)
+
+	h := di.Set(c, di.OptSetup(func() (int, error) {
+		return 1, nil
+	}), di.OptCleanup(func(int) error {
+		cleaned++
+		return nil
+	}))
+
+	h.ReplaceSetup(func() (int, error) {
+		return 20, nil
+	}).AddMiddleware(func(val int) (int, error) {
+		return val + 1, nil
+	})
+
+	if val := di.Get[int](c); val != 21 {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if e := h.Entity(); e.String() != "<int>" || !e.Built {
+		t.Errorf("Unexpected: %v", e)
+	}
+
+	if err := h.Remove(); err != nil || cleaned != 1 {
+		t.Errorf("Unexpected: %v, %v", err, cleaned)
+	}
+
+	if _, err := di.TryGet[int](c); err == nil {
+		t.Errorf("Entity should be removed")
+	}
+}
+
+func TestHandleRemoveReplaced(t *testing.T) {
+	c := di.New()
+
+	old := di.SetNamed(c, "answer", di.OptSetup(func() (int, error) { return 1, nil }))
+	if err := old.Remove(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	di.SetNamed(c, "answer", di.OptSetup(func() (int, error) { return 2, nil }))
+	if err := old.Remove(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if val := di.GetNamed[int](c, "answer"); val != 2 {
+		t.Errorf("Unexpected: %v", val)
+	}
+}
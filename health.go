@@ -0,0 +1,128 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrorBudget makes container unhealthy once n setups failed, e.g. of per
+// request transients, so orchestrator could recycle the instance, see Health.
+// Failures of scopes are counted by their root container, n <= 0 disables it.
+func (c *Container) ErrorBudget(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.errorBudget = n
+}
+
+// Health returns error once error budget is exhausted, Restart resets it
+func (c *Container) Health() error {
+	root := c.root()
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+
+	if root.errorBudget <= 0 || root.failures < root.errorBudget {
+		return nil
+	}
+
+	return fmt.Errorf("unhealthy: %d setups failed, the last: %w", root.failures, root.lastFailure)
+}
+
+func (c *Container) countFailure(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failures++
+	c.lastFailure = err
+}
+
+func (c *Container) resetFailures() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failures, c.lastFailure = 0, nil
+}
+
+// OptLiveness adds check of built instance run by Container.Live, it should
+// fail on fatal states only, e.g. of stuck worker, which restart fixes
+func OptLiveness[T any](check func(context.Context, T) error) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.liveness = append(s.liveness, check) }
+}
+
+// OptReadiness adds check of built instance run by Container.Ready, e.g. of
+// connection which is down for a while
+func OptReadiness[T any](check func(context.Context, T) error) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.readiness = append(s.readiness, check) }
+}
+
+// Live returns error once container is failed, see Strict, its error budget
+// is exhausted or liveness check of built entity fails, so orchestrator
+// should restart the instance
+func (c *Container) Live(ctx context.Context) error {
+	root := c.root()
+	if err := root.failure(); err != nil {
+		return err
+	}
+
+	if err := root.Health(); err != nil {
+		return err
+	}
+
+	return root.probe(ctx, false)
+}
+
+// Ready returns error while Init or Reload is in progress, eager entities
+// aren't built yet, readiness check of built entity fails or container isn't
+// live, so orchestrator shouldn't route traffic to the instance
+func (c *Container) Ready(ctx context.Context) error {
+	root := c.root()
+	root.mu.RLock()
+	transitions := root.transitions
+	root.mu.RUnlock()
+
+	if transitions > 0 {
+		return errors.New("not ready: init or reload in progress")
+	}
+
+	if eager := root.query(func(e Entity) bool { return e.Eager && !e.Built }); len(eager) > 0 {
+		return fmt.Errorf("not ready: eager entities aren't built: %v", eager)
+	}
+
+	if err := c.Live(ctx); err != nil {
+		return err
+	}
+
+	return root.probe(ctx, true)
+}
+
+// probe entities registered in c
+func (c *Container) probe(ctx context.Context, readiness bool) error {
+	c.mu.RLock()
+	entities := make([]entity, 0, len(c.entities))
+	for _, entity := range c.entities {
+		entities = append(entities, entity)
+	}
+	c.mu.RUnlock()
+
+	errs := []error{}
+	for _, entity := range entities {
+		errs = append(errs, entity.probe(ctx, readiness))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *Container) beginTransition() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.transitions++
+}
+
+func (c *Container) endTransition() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.transitions--
+}
@@ -0,0 +1,147 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestHealth(t *testing.T) {
+	var (
+		c       = di.New()
+		errTest = errors.New("test")
+	)
+
+	c.ErrorBudget(2)
+	di.Set(c, di.OptSetup(func() (int, error) {
+		return 0, errTest
+	}), di.OptNoReuse[int]())
+
+	for range 2 {
+		if err := c.Health(); err != nil {
+			t.Errorf("Unexpected: %v", err)
+		}
+
+		s := c.BeginScope()
+		if _, err := di.TryGet[int](s.Container); err == nil {
+			t.Errorf("Error expected")
+		}
+		_ = s.End()
+	}
+
+	if err := c.Health(); !errors.Is(err, errTest) {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if err := c.Restart(context.Background()); err != nil || c.Health() != nil {
+		t.Errorf("Unexpected: %v, %v", err, c.Health())
+	}
+}
+
+func TestHealthReplayedError(t *testing.T) {
+	var (
+		c       = di.New()
+		errTest = errors.New("test")
+	)
+
+	c.ErrorBudget(2)
+	di.Set(c, di.OptSetup(func() (int, error) {
+		return 0, errTest
+	}))
+
+	for range 3 {
+		if _, err := di.TryGet[int](c); !errors.Is(err, errTest) {
+			t.Errorf("Unexpected: %v", err)
+		}
+	}
+
+	if err := c.Health(); err != nil {
+		t.Errorf("Remembered error shouldn't be counted again: %v", err)
+	}
+}
+
+func TestLiveReady(t *testing.T) {
+	var (
+		c        = di.New()
+		ctx      = context.Background()
+		down     = errors.New("down")
+		stuck    = errors.New("stuck")
+		connDown error
+		workerOK = true
+	)
+
+	di.Set(c, di.OptSetup(func() (string, error) {
+		return "conn", nil
+	}), di.OptReadiness(func(context.Context, string) error {
+		return connDown
+	}), di.OptEager[string]())
+	di.Set(c, di.OptSetup(func() (int, error) {
+		return 42, nil
+	}), di.OptLiveness(func(context.Context, int) error {
+		if !workerOK {
+			return stuck
+		}
+
+		return nil
+	}))
+
+	if err := c.Live(ctx); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if err := c.Ready(ctx); err == nil {
+		t.Errorf("Ready should fail before Init")
+	}
+
+	if err := c.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Ready(ctx); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	connDown = down
+	if err := c.Ready(ctx); !errors.Is(err, down) {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if err := c.Live(ctx); err != nil {
+		t.Errorf("Readiness shouldn't affect liveness: %v", err)
+	}
+
+	// liveness of unbuilt entity isn't checked
+	workerOK = false
+	if err := c.Live(ctx); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	di.Get[int](c)
+	if err := c.Live(ctx); !errors.Is(err, stuck) {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
+
+func TestReadyDuringInit(t *testing.T) {
+	var (
+		c       = di.New()
+		ctx     = context.Background()
+		ready   error
+		checked = false
+	)
+
+	di.Set(c, di.OptSetupC(func(c *di.Container) (string, error) {
+		ready, checked = c.Ready(ctx), true
+		return "", nil
+	}), di.OptEager[string]())
+
+	if err := c.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if !checked || ready == nil {
+		t.Errorf("Ready should fail during Init")
+	}
+}
@@ -0,0 +1,230 @@
+package di
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type (
+	// CleanupEvent is passed to cleanup hooks before (Done is false) and
+	// after each entity cleanup
+	CleanupEvent struct {
+		Entity   Entity
+		Done     bool
+		Duration time.Duration
+		Err      error
+	}
+	// GetEvent is passed to Get hooks for each entity being resolved
+	GetEvent struct {
+		Entity Entity
+		// Site is file:line of Get call
+		Site string
+	}
+	// CleanupPolicy is decision of OnCleanupError handler
+	CleanupPolicy int
+	// CleanupAbortedError is returned by aborted Cleanup, it lists entities
+	// which remain un-cleaned
+	CleanupAbortedError struct {
+		Err       error
+		Remaining []Entity
+	}
+	// CleanupHungError is returned by CleanupContext once ctx is done before
+	// entity cleanup returned. Cleanup is left running, the remaining ones
+	// are left for the next Cleanup call.
+	CleanupHungError struct {
+		Err    error
+		Entity Entity
+		// Running is how long cleanup had been running
+		Running   time.Duration
+		Remaining []Entity
+	}
+)
+
+const (
+	// CleanupContinue with the next entity
+	CleanupContinue CleanupPolicy = iota
+	// CleanupRetry the failed entity cleanup, up to MaxCleanupRetries times
+	CleanupRetry
+	// CleanupAbort leaving the rest of entities for the next Cleanup call
+	CleanupAbort
+)
+
+// MaxCleanupRetries of entity cleanup, once they're exhausted Cleanup
+// continues with the next entity as CleanupContinue does
+const MaxCleanupRetries = 3
+
+// OnCleanup adds hook called around each entity cleanup, e.g. to log shutdown
+// progress and spot stuck "destructor". Hooks of container are used for its
+// scopes as well.
+func (c *Container) OnCleanup(hook func(CleanupEvent)) {
+	c.cleanupHooks = append(c.cleanupHooks, hook)
+}
+
+// OnGet adds hook called for each entity being resolved through c or its
+// scopes, including nested Gets of setups, e.g. to record which code touches
+// which entities
+func (c *Container) OnGet(hook func(GetEvent)) {
+	c.getHooks = append(c.getHooks, hook)
+}
+
+// OptBeforeGet adds hook called each time entity is resolved, before it's
+// built or taken from cache, e.g. to count lookups of particular client.
+// Error of hook fails the Get.
+func OptBeforeGet[T any](hook func() error) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.beforeGet = append(s.beforeGet, hook) }
+}
+
+// OptAfterGet adds hook called each time entity is resolved, with instance
+// being handed out, e.g. to check credentials of client haven't expired.
+// Error of hook fails the Get, built instance is kept for the next one.
+func OptAfterGet[T any](hook func(T) error) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.afterGet = append(s.afterGet, hook) }
+}
+
+// OnSlowSetup sets handler called whenever entity setup takes longer than
+// threshold, e.g. to catch boot time regressions. Nil handler logs by
+// slog.Default. Duration includes nested Gets made by setup. Scope without
+// handler of its own uses the one of its parent.
+func (c *Container) OnSlowSetup(threshold time.Duration, handler func(Entity, time.Duration)) {
+	if handler == nil {
+		handler = func(e Entity, d time.Duration) {
+			slog.Warn("slow entity setup", "entity", e, "duration", d, "site", e.Site)
+		}
+	}
+
+	c.slowSetup, c.slowSetupHandler = threshold, handler
+}
+
+func (c *Container) reportSlowSetup(entity entity, d time.Duration) {
+	for owner := c; owner != nil; owner = owner.parent {
+		if owner.slowSetupHandler == nil {
+			continue
+		}
+
+		if d > owner.slowSetup {
+			owner.slowSetupHandler(entity.info(), d)
+		}
+
+		return
+	}
+}
+
+// notifyGet hooks of c and its parents
+func (c *Container) notifyGet(entity entity) {
+	var event *GetEvent
+	for owner := c; owner != nil; owner = owner.parent {
+		for _, hook := range owner.getHooks {
+			if event == nil {
+				event = &GetEvent{Entity: entity.info(), Site: callerSite()}
+			}
+
+			hook(*event)
+		}
+	}
+}
+
+// OnCleanupError sets handler called for each failed entity cleanup, it
+// decides whether to continue, retry or abort. Scope without handler of its
+// own uses the one of its parent. By default Cleanup continues.
+func (c *Container) OnCleanupError(handler func(Entity, error) CleanupPolicy) {
+	c.cleanupErrHandler = handler
+}
+
+// FailFast is OnCleanupError handler which aborts Cleanup on the first error
+func FailFast(Entity, error) CleanupPolicy {
+	return CleanupAbort
+}
+
+func (e *CleanupAbortedError) Error() string {
+	return fmt.Sprintf("%v (cleanup aborted, remaining: %v)", e.Err, e.Remaining)
+}
+
+func (e *CleanupAbortedError) Unwrap() error { return e.Err }
+
+func (e *CleanupHungError) Error() string {
+	return fmt.Sprintf("cleanup %v (%s) hung for %v: %v (remaining: %v)",
+		e.Entity, e.Entity.Site, e.Running.Round(time.Millisecond), e.Err, e.Remaining)
+}
+
+func (e *CleanupHungError) Unwrap() error { return e.Err }
+
+// runCleanupContext is runCleanup which stops waiting once ctx is done,
+// reporting cleanup as hung
+func (c *Container) runCleanupContext(ctx context.Context, cleanup cleanup) (abort bool, err error, hung bool) {
+	if ctx.Done() == nil {
+		abort, err = c.runCleanup(cleanup)
+		return abort, err, false
+	}
+
+	type result struct {
+		abort bool
+		err   error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		abort, err := c.runCleanup(cleanup)
+		done <- result{abort: abort, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.abort, r.err, false
+	case <-ctx.Done():
+		return false, ctx.Err(), true
+	}
+}
+
+// runCleanup calls hooks and error handler around entity cleanup,
+// returned error is attributed to entity
+func (c *Container) runCleanup(cleanup cleanup) (abort bool, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("cleanup %v (%s): %w", cleanup.entity, cleanup.entity.Site, err)
+		}
+	}()
+
+	var (
+		hooks   []func(CleanupEvent)
+		handler func(Entity, error) CleanupPolicy
+	)
+
+	for owner := c; owner != nil; owner = owner.parent {
+		hooks = append(hooks, owner.cleanupHooks...)
+		if handler == nil {
+			handler = owner.cleanupErrHandler
+		}
+	}
+
+	for retries := 0; ; retries++ {
+		for _, hook := range hooks {
+			hook(CleanupEvent{Entity: cleanup.entity})
+		}
+
+		start := time.Now()
+		err = cleanup.fn()
+
+		for _, hook := range hooks {
+			hook(CleanupEvent{Entity: cleanup.entity, Done: true, Duration: time.Since(start), Err: err})
+		}
+
+		if err == nil || handler == nil {
+			return false, err
+		}
+
+		switch handler(cleanup.entity, err) {
+		case CleanupRetry:
+			if retries < MaxCleanupRetries {
+				continue
+			}
+
+			return false, err
+		case CleanupAbort:
+			return true, err
+		default:
+			return false, err
+		}
+	}
+}
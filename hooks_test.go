@@ -0,0 +1,330 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/irr123/di"
+)
+
+func TestOnCleanup(t *testing.T) {
+	var (
+		c      = di.New()
+		events = []string{}
+	)
+
+	c.OnCleanup(func(e di.CleanupEvent) {
+		events = append(events, fmt.Sprintf("%v %v %v", e.Entity, e.Done, e.Err))
+	})
+
+	di.SetNamed(c, "name", di.OptSetup(func() (int, error) {
+		return 42, nil
+	}), di.OptCleanup(func(int) error {
+		return errors.New("failed")
+	}))
+	di.Set(c, di.OptSetup(func() (string, error) {
+		return fmt.Sprint(di.GetNamed[int](c, "name")), nil
+	}))
+
+	di.Get[string](c)
+	di.Get[string](c)
+
+	if err := c.Cleanup(); err == nil {
+		t.Errorf("Cleanup should return error")
+	}
+
+	if fmt.Sprint(events) != "[name<int> false <nil> name<int> true failed]" {
+		t.Errorf("Unexpected: %v", events)
+	}
+}
+
+func TestOnCleanupError(t *testing.T) {
+	var (
+		c       = di.New()
+		retried = 0
+		cleaned = []string{}
+	)
+
+	c.OnCleanupError(func(e di.Entity, err error) di.CleanupPolicy {
+		switch {
+		case e.Name == "flaky" && retried < 2:
+			retried++
+			return di.CleanupRetry
+		case e.Name == "broken":
+			return di.CleanupAbort
+		}
+
+		return di.CleanupContinue
+	})
+
+	for _, name := range []string{"last", "broken", "flaky"} {
+		di.SetNamed(c, name, di.OptSetup(func() (string, error) {
+			return name, nil
+		}), di.OptCleanup(func(s string) error {
+			cleaned = append(cleaned, s)
+			if s != "flaky" || retried < 2 {
+				return errors.New(s)
+			}
+			return nil
+		}))
+		di.GetNamed[string](c, name)
+	}
+
+	err := c.Cleanup()
+	if aborted := new(di.CleanupAbortedError); !errors.As(err, &aborted) || fmt.Sprint(aborted.Remaining) != "[last<string>]" {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if fmt.Sprint(cleaned) != "[flaky flaky flaky broken]" {
+		t.Errorf("Unexpected: %v", cleaned)
+	}
+
+	c.OnCleanupError(nil)
+	if err := c.Cleanup(); err == nil {
+		t.Errorf("Cleanup should return error")
+	}
+
+	if fmt.Sprint(cleaned) != "[flaky flaky flaky broken last]" {
+		t.Errorf("Unexpected: %v", cleaned)
+	}
+}
+
+func TestCleanupRetryLimit(t *testing.T) {
+	var (
+		c        = di.New()
+		attempts = 0
+	)
+
+	c.OnCleanupError(func(di.Entity, error) di.CleanupPolicy { return di.CleanupRetry })
+
+	di.Set(c, di.OptSetup(func() (string, error) {
+		return "broken", nil
+	}), di.OptCleanup(func(string) error {
+		attempts++
+		return errors.New("broken")
+	}))
+	di.Get[string](c)
+
+	if err := c.Cleanup(); err == nil || attempts != di.MaxCleanupRetries+1 {
+		t.Errorf("Unexpected: %v, %v", err, attempts)
+	}
+}
+
+func TestFailFast(t *testing.T) {
+	var (
+		c       = di.New()
+		cleaned = []string{}
+	)
+
+	c.OnCleanupError(di.FailFast)
+
+	for _, name := range []string{"db", "cache", "server"} {
+		di.SetNamed(c, name, di.OptSetup(func() (string, error) {
+			return name, nil
+		}), di.OptCleanup(func(s string) error {
+			cleaned = append(cleaned, s)
+			return errors.New(s)
+		}))
+		di.GetNamed[string](c, name)
+	}
+
+	err := c.Cleanup()
+	if err == nil || !strings.HasSuffix(err.Error(), ": server (cleanup aborted, remaining: [cache<string> db<string>])") {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if fmt.Sprint(cleaned) != "[server]" {
+		t.Errorf("Unexpected: %v", cleaned)
+	}
+}
+
+func TestOnGet(t *testing.T) {
+	var (
+		c      = di.New()
+		events = []di.GetEvent{}
+	)
+
+	c.OnGet(func(e di.GetEvent) { events = append(events, e) })
+
+	di.Set(c, di.OptSetup(func() (int, error) { return 42, nil }))
+	di.Set(c, di.OptSetup(func() (string, error) {
+		return strconv.Itoa(di.Get[int](c)), nil
+	}))
+
+	di.Get[string](c.BeginScope().Container)
+
+	if len(events) != 2 || events[0].Entity.String() != "<string>" || events[1].Entity.String() != "<int>" {
+		t.Errorf("Unexpected: %v", events)
+	}
+
+	if !strings.Contains(events[0].Site, "hooks_test.go") {
+		t.Errorf("Unexpected: %v", events[0].Site)
+	}
+}
+
+func TestOnSlowSetup(t *testing.T) {
+	var (
+		c    = di.New()
+		slow = []string{}
+	)
+
+	c.OnSlowSetup(5*time.Millisecond, func(e di.Entity, d time.Duration) {
+		slow = append(slow, e.String())
+	})
+
+	di.SetNamed(c, "slow", di.OptSetup(func() (int, error) {
+		time.Sleep(10 * time.Millisecond)
+		return 1, nil
+	}))
+	di.SetNamed(c, "fast", di.OptSetup(func() (int, error) { return 2, nil }))
+
+	di.GetNamed[int](c.BeginScope().Container, "slow")
+	di.GetNamed[int](c, "fast")
+
+	if len(slow) != 1 || slow[0] != "slow<int>" {
+		t.Errorf("Unexpected: %v", slow)
+	}
+}
+
+func TestCleanupHung(t *testing.T) {
+	type (
+		stuck struct{}
+		other struct{}
+	)
+
+	var (
+		c       = di.New()
+		release = make(chan struct{})
+		cleaned = false
+	)
+	defer close(release)
+
+	di.Set(c, di.OptSetup(func() (other, error) {
+		return other{}, nil
+	}), di.OptCleanup(func(other) error {
+		cleaned = true
+		return nil
+	}))
+	di.Set(c, di.OptSetupC(func(c *di.Container) (stuck, error) {
+		di.Get[other](c)
+		return stuck{}, nil
+	}), di.OptCleanup(func(stuck) error {
+		<-release
+		return nil
+	}))
+
+	di.Get[stuck](c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := c.CleanupContext(ctx)
+
+	var hung *di.CleanupHungError
+	if !errors.As(err, &hung) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Unexpected: %v", err)
+	}
+
+	if hung.Entity.Type != reflect.TypeFor[stuck]() || hung.Running <= 0 {
+		t.Errorf("Unexpected: %+v", hung)
+	}
+
+	if len(hung.Remaining) != 1 || hung.Remaining[0].Type != reflect.TypeFor[other]() || cleaned {
+		t.Errorf("Unexpected: %v", hung.Remaining)
+	}
+
+	if pending := c.Pending(); len(pending) != 1 {
+		t.Errorf("Remaining cleanups should be pending: %v", pending)
+	}
+}
+
+func TestOptBeforeAfterGet(t *testing.T) {
+	var (
+		c       = di.New()
+		errTest = errors.New("expired")
+		gets    = 0
+		valid   = false
+		cleaned = 0
+	)
+
+	di.Set(c, di.OptSetup(func() (string, error) {
+		return "client", nil
+	}), di.OptCleanup(func(string) error {
+		cleaned++
+		return nil
+	}), di.OptBeforeGet[string](func() error {
+		gets++
+		return nil
+	}), di.OptAfterGet(func(s string) error {
+		if !valid {
+			return errTest
+		}
+
+		return nil
+	}))
+
+	if _, err := di.TryGet[string](c); !errors.Is(err, errTest) {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	valid = true
+	if val, err := di.TryGet[string](c); err != nil || val != "client" {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+
+	if gets != 2 {
+		t.Errorf("Unexpected: %v", gets)
+	}
+
+	if err := c.Cleanup(); err != nil || cleaned != 1 {
+		t.Errorf("Unexpected: %v, %v", err, cleaned)
+	}
+}
+
+func TestScopeHooks(t *testing.T) {
+	var (
+		c      = di.New()
+		scope  = c.BeginScope()
+		events = []string{}
+	)
+
+	c.OnCleanup(func(e di.CleanupEvent) {
+		if e.Done {
+			events = append(events, "root")
+		}
+	})
+	scope.OnCleanup(func(e di.CleanupEvent) {
+		if e.Done {
+			events = append(events, "scope")
+		}
+	})
+	scope.OnCleanupError(di.FailFast)
+	scope.OnNotFound(func(di.Entity) (any, bool) { return 42, true })
+
+	di.Set(scope.Container, di.OptSetup(func() (string, error) {
+		return "conn", nil
+	}), di.OptCleanup(func(string) error { return errors.New("close") }))
+	di.Get[string](scope.Container)
+
+	if val := di.Get[int](scope.Container); val != 42 {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if _, err := di.TryGet[int](c); err == nil {
+		t.Errorf("Provider of scope shouldn't be used by its parent")
+	}
+
+	if err := scope.End(); !errors.As(err, new(*di.CleanupAbortedError)) {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if fmt.Sprint(events) != "[scope root]" {
+		t.Errorf("Unexpected: %v", events)
+	}
+}
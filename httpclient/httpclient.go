@@ -0,0 +1,44 @@
+// Package httpclient builds *http.Client whose transport is composed of
+// middlewares contributed by separate modules, e.g. retries, tracing and auth.
+package httpclient
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/irr123/di"
+)
+
+// Middleware wraps transport
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// Use registers named middleware of clients, middlewares are applied in order
+// of their names, the first one is the outermost, e.g. "10-tracing" wraps
+// "20-retry". Setup gets dependencies of middleware from container.
+func Use(c *di.Container, name string, setup func(*di.Container) (Middleware, error)) {
+	di.SetNamed(c, name, di.OptSetupC(setup))
+}
+
+// Register named *http.Client entity with timeout, its transport is
+// http.RoundTripper entity, or http.DefaultTransport if there is none, wrapped
+// by middlewares registered by Use. Idle connections are closed on cleanup.
+func Register(c *di.Container, name string, timeout time.Duration) {
+	di.SetNamed(c, name, di.OptSetupC(func(c *di.Container) (*http.Client, error) {
+		transport := di.GetOrDefault(c, http.DefaultTransport)
+
+		names := di.ListNamed[Middleware](c)
+		for i := len(names) - 1; i >= 0; i-- {
+			mw, err := di.TryGetNamed[Middleware](c, names[i])
+			if err != nil {
+				return nil, err
+			}
+
+			transport = mw(transport)
+		}
+
+		return &http.Client{Transport: transport, Timeout: timeout}, nil
+	}), di.OptCleanup(func(client *http.Client) error {
+		client.CloseIdleConnections()
+		return nil
+	}))
+}
@@ -0,0 +1,61 @@
+package httpclient_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/irr123/di"
+	"github.com/irr123/di/httpclient"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func header(name string) httpclient.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			r.Header.Add("X-Chain", name)
+			return next.RoundTrip(r)
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Join(r.Header.Values("X-Chain"), ",")))
+	}))
+	defer server.Close()
+
+	c := di.New()
+
+	httpclient.Register(c, "", time.Second)
+	for _, name := range []string{"20-retry", "10-tracing", "30-auth"} {
+		httpclient.Use(c, name, func(*di.Container) (httpclient.Middleware, error) {
+			return header(name), nil
+		})
+	}
+
+	resp, err := di.Get[*http.Client](c).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chain := string(body); chain != "10-tracing,20-retry,30-auth" {
+		t.Errorf("Unexpected: %v", chain)
+	}
+
+	if err := c.Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,146 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// In marks struct parameter of Provide constructor, whose exported fields are
+// got from container instead of the struct itself. Field tagged
+// `di:"name=replica"` gets named entity, `di:"-"` one is skipped. Optional
+// field, e.g. `di:"name=replica,optional"`, is left zero when its entity
+// isn't registered, failed setup fails it as usual.
+//
+//	type RepoParams struct {
+//		di.In
+//		Primary *sql.DB
+//		Replica *sql.DB `di:"name=replica"`
+//	}
+type In struct{}
+
+var inType = reflect.TypeFor[In]()
+
+// isIn tells if typ is struct embedding In
+func isIn(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := range typ.NumField() {
+		if f := typ.Field(i); f.Anonymous && f.Type == inType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fieldTag is parsed `di` tag of struct field
+type fieldTag struct {
+	name     string
+	skip     bool
+	optional bool
+}
+
+func parseFieldTag(f reflect.StructField) (fieldTag, error) {
+	tag := fieldTag{}
+	value, ok := f.Tag.Lookup("di")
+	if !ok || value == "" {
+		return tag, nil
+	}
+
+	if value == "-" {
+		tag.skip = true
+		return tag, nil
+	}
+
+	for _, option := range strings.Split(value, ",") {
+		switch key, val, _ := strings.Cut(option, "="); key {
+		case "name":
+			tag.name = val
+		case "optional":
+			tag.optional = true
+		default:
+			return tag, fmt.Errorf("field %s: unknown di tag option %q", f.Name, option)
+		}
+	}
+
+	return tag, nil
+}
+
+// Inject fills fields of struct target points to which are tagged by `di`,
+// e.g. `di:""` or `di:"name=replica,optional"`, see In. Target itself isn't
+// registered, so it fits objects constructed by frameworks, like message
+// handlers or test suites.
+func Inject(c *Container, target any) (err error) {
+	defer recoverResolveError(&err)
+
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("inject: %T is not pointer to struct", target)
+	}
+
+	return c.fill(v.Elem(), true)
+}
+
+// fill exported fields of struct v from container, tagged ones only if told
+func (c *Container) fill(v reflect.Value, tagged bool) error {
+	typ := v.Type()
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+		if _, ok := f.Tag.Lookup("di"); f.Type == inType || (tagged && !ok) {
+			continue
+		}
+
+		if !f.IsExported() {
+			if tagged {
+				return fmt.Errorf("field %s: unexported field can't be injected", f.Name)
+			}
+
+			continue
+		}
+
+		tag, err := parseFieldTag(f)
+		if err != nil {
+			return err
+		}
+
+		if tag.skip {
+			continue
+		}
+
+		if tag.optional && !c.resolvable(f.Type, tag.name) {
+			continue
+		}
+
+		val, err := c.resolveType(f.Type, tag.name)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+
+		if val != nil {
+			v.Field(i).Set(reflect.ValueOf(val))
+		}
+	}
+
+	return nil
+}
+
+// NewOf builds struct T getting each of its exported fields from container,
+// tags are honored as by In, e.g. for plain holder of dependencies which
+// doesn't deserve constructor. T isn't registered.
+func NewOf[T any](c *Container) (val T, err error) {
+	defer recoverResolveError(&err)
+
+	v := reflect.ValueOf(&val).Elem()
+	if v.Kind() != reflect.Struct {
+		return val, fmt.Errorf("new: %v is not struct", v.Type())
+	}
+
+	if err := c.fill(v, false); err != nil {
+		return empty[T](), err
+	}
+
+	return val, nil
+}
@@ -0,0 +1,161 @@
+package di_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+type repoParams struct {
+	di.In
+	Primary *providedDB
+	Replica *providedDB `di:"name=replica"`
+	Skipped *providedDB `di:"-"`
+	private *providedDB
+}
+
+type repo struct{ primary, replica, skipped, private *providedDB }
+
+func TestProvideIn(t *testing.T) {
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (*providedDB, error) { return &providedDB{dsn: "primary"}, nil }))
+	di.SetNamed(c, "replica", di.OptSetup(func() (*providedDB, error) { return &providedDB{dsn: "replica"}, nil }))
+
+	if err := di.Provide(c, func(p repoParams) *repo {
+		return &repo{primary: p.Primary, replica: p.Replica, skipped: p.Skipped, private: p.private}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	val := di.Get[*repo](c)
+	if val.primary.dsn != "primary" || val.replica.dsn != "replica" || val.skipped != nil || val.private != nil {
+		t.Errorf("Unexpected: %+v", val)
+	}
+}
+
+func TestProvideInErrors(t *testing.T) {
+	type params struct {
+		di.In
+		DB *providedDB `di:"nmae=replica"`
+	}
+
+	c := di.New()
+	di.Set(c, di.OptSetup(func() (*providedDB, error) { return &providedDB{}, nil }))
+
+	if err := di.Provide(c, func(params) string { return "" }); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := di.TryGet[string](c); err == nil || !strings.Contains(err.Error(), "unknown di tag option") {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
+
+func TestProvideInOptional(t *testing.T) {
+	type params struct {
+		di.In
+		Primary *providedDB `di:"optional"`
+		Replica *providedDB `di:"name=replica,optional"`
+	}
+
+	var (
+		c       = di.New()
+		errTest = errors.New("test")
+	)
+
+	di.Set(c, di.OptSetup(func() (*providedDB, error) { return &providedDB{dsn: "primary"}, nil }))
+
+	if err := di.Provide(c, func(p params) (*repo, error) {
+		return &repo{primary: p.Primary, replica: p.Replica}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if val := di.Get[*repo](c); val.primary.dsn != "primary" || val.replica != nil {
+		t.Errorf("Unexpected: %+v", val)
+	}
+
+	// registered optional dependency still fails on failed setup
+	di.SetNamed(c, "replica", di.OptSetup(func() (*providedDB, error) { return nil, errTest }))
+	if err := di.Reset[*repo](c); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := di.TryGet[*repo](c); !errors.Is(err, errTest) {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
+
+func TestInject(t *testing.T) {
+	type handler struct {
+		DB      *providedDB `di:""`
+		Replica *providedDB `di:"name=replica,optional"`
+		Name    string
+	}
+
+	c := di.New()
+	di.Set(c, di.OptSetup(func() (*providedDB, error) { return &providedDB{dsn: "primary"}, nil }))
+
+	h := handler{Name: "kept"}
+	if err := di.Inject(c, &h); err != nil {
+		t.Fatal(err)
+	}
+
+	if h.DB.dsn != "primary" || h.Replica != nil || h.Name != "kept" {
+		t.Errorf("Unexpected: %+v", h)
+	}
+
+	if entities := c.Entities(); len(entities) != 1 {
+		t.Errorf("Target shouldn't be registered: %v", entities)
+	}
+}
+
+func TestInjectErrors(t *testing.T) {
+	type (
+		missing struct {
+			DB *providedDB `di:""`
+		}
+		unexported struct {
+			db *providedDB `di:""`
+		}
+	)
+
+	c := di.New()
+
+	for _, target := range []any{nil, missing{}, new(int), &missing{}, &unexported{}} {
+		if err := di.Inject(c, target); err == nil {
+			t.Errorf("Inject should fail on %T", target)
+		}
+	}
+}
+
+func TestNewOf(t *testing.T) {
+	type deps struct {
+		DB      *providedDB
+		Replica *providedDB `di:"name=replica,optional"`
+		Name    string      `di:"-"`
+	}
+
+	c := di.New()
+	di.Set(c, di.OptSetup(func() (*providedDB, error) { return &providedDB{dsn: "primary"}, nil }))
+
+	val, err := di.NewOf[deps](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if val.DB.dsn != "primary" || val.Replica != nil {
+		t.Errorf("Unexpected: %+v", val)
+	}
+
+	if _, err := di.NewOf[int](c); err == nil {
+		t.Errorf("NewOf should fail on non-struct")
+	}
+
+	if _, err := di.NewOf[struct{ S string }](c); err == nil {
+		t.Errorf("NewOf should fail on missing dependency")
+	}
+}
@@ -0,0 +1,51 @@
+package di
+
+import "reflect"
+
+// Resolver resolves entity, see Use
+type Resolver func(Entity) (any, error)
+
+// Use adds interceptor wrapping resolution of every entity through c or its
+// scopes, including nested Gets of setups, e.g. to log, measure or deny
+// lookups. Interceptor may return error instead of calling next, value it
+// returns has to be of entity type, Get fails otherwise. The first added
+// interceptor is the outermost one, interceptors of root container wrap the
+// ones of its scopes.
+func (c *Container) Use(interceptor func(next Resolver) Resolver) {
+	c.interceptors = append(c.interceptors, interceptor)
+}
+
+// assignable tells if val could be value of entity of type typ
+func assignable(val any, typ reflect.Type) bool {
+	if val == nil {
+		switch typ.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+			return true
+		default:
+			return false
+		}
+	}
+
+	return reflect.TypeOf(val).AssignableTo(typ)
+}
+
+func (c *Container) intercepted() bool {
+	for owner := c; owner != nil; owner = owner.parent {
+		if len(owner.interceptors) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// intercept wraps resolver by interceptors of c and its parents
+func (c *Container) intercept(resolver Resolver) Resolver {
+	for owner := c; owner != nil; owner = owner.parent {
+		for i := len(owner.interceptors) - 1; i >= 0; i-- {
+			resolver = owner.interceptors[i](resolver)
+		}
+	}
+
+	return resolver
+}
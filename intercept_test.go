@@ -0,0 +1,94 @@
+package di_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestUse(t *testing.T) {
+	var (
+		c       = di.New()
+		errTest = errors.New("denied")
+		log     = []string{}
+	)
+
+	di.Set(c, di.OptSetup(func() (int, error) {
+		return 42, nil
+	}))
+	di.Set(c, di.OptSetupC(func(c *di.Container) (string, error) {
+		return strings.Repeat("x", di.Get[int](c)/21), nil
+	}))
+	di.SetNamed(c, "secret", di.OptSetup(func() (string, error) {
+		return "secret", nil
+	}))
+
+	for _, tag := range []string{"outer", "inner"} {
+		c.Use(func(next di.Resolver) di.Resolver {
+			return func(e di.Entity) (any, error) {
+				log = append(log, tag+" "+e.String())
+				return next(e)
+			}
+		})
+	}
+
+	scope := c.BeginScope()
+	scope.Use(func(next di.Resolver) di.Resolver {
+		return func(e di.Entity) (any, error) {
+			if e.Name == "secret" {
+				return nil, errTest
+			}
+
+			return next(e)
+		}
+	})
+
+	if val := di.Get[string](scope.Container); val != "xx" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	expected := "outer <string>,inner <string>,outer <int>,inner <int>"
+	if got := strings.Join(log, ","); got != expected {
+		t.Errorf("Unexpected: %v", got)
+	}
+
+	if _, err := di.TryGetNamed[string](scope.Container, "secret"); !errors.Is(err, errTest) {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if val := di.GetNamed[string](c, "secret"); val != "secret" {
+		t.Errorf("Unexpected: %v", val)
+	}
+}
+
+func TestUseWrongType(t *testing.T) {
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (int, error) {
+		return 42, nil
+	}))
+	di.Set(c, di.OptSetup(func() (*int, error) {
+		return nil, nil
+	}))
+
+	c.Use(func(next di.Resolver) di.Resolver {
+		return func(e di.Entity) (any, error) {
+			if e.Type.Kind() == reflect.Int {
+				return "chaos", nil
+			}
+
+			return next(e)
+		}
+	})
+
+	if val, err := di.TryGet[int](c); err == nil || !strings.Contains(err.Error(), "interceptor returned string for <int>") {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+
+	if val, err := di.TryGet[*int](c); err != nil || val != nil {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+}
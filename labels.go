@@ -0,0 +1,14 @@
+package di
+
+import "slices"
+
+// OptLabels marks entity, so it could be found by Container.ByLabel
+func OptLabels[T any](labels ...string) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.labels = append(s.labels, labels...) }
+}
+
+// ByLabel returns entities marked by label, e.g. to health-check or warm
+// them up all at once
+func (c *Container) ByLabel(label string) []Entity {
+	return c.query(func(e Entity) bool { return slices.Contains(e.Labels, label) })
+}
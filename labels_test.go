@@ -0,0 +1,40 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestByLabel(t *testing.T) {
+	type (
+		db    string
+		cache string
+	)
+
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (db, error) {
+		return "db", nil
+	}), di.OptLabels[db]("critical", "storage"))
+	di.Set(c, di.OptSetup(func() (cache, error) {
+		return "cache", nil
+	}), di.OptLabels[cache]("storage"))
+	di.Set(c, di.OptSetup(func() (string, error) {
+		panic("no need to init 'string'")
+	}))
+
+	storage := c.ByLabel("storage")
+	if len(storage) != 2 {
+		t.Fatalf("Unexpected: %v", storage)
+	}
+
+	critical := c.ByLabel("critical")
+	if len(critical) != 1 || critical[0].Type.Name() != "db" {
+		t.Fatalf("Unexpected: %v", critical)
+	}
+
+	if val, err := critical[0].Get(); err != nil || val != db("db") {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+}
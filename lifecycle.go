@@ -0,0 +1,151 @@
+package di
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// OptEager entity is built by Container.Init instead of on the first Get
+func OptEager[T any]() func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.eager = true }
+}
+
+// OptDrain adds drain of built instance, which runs before cleanup begins, in
+// order instances were built, so e.g. server stops accepting work and flushes
+// queues while its dependencies still exist. Cleanup drains with background
+// context, CleanupContext with the given one.
+func OptDrain[T any](drain func(context.Context, T) error) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.drains = append(s.drains, drain) }
+}
+
+// Init builds eager entities in order they were registered, so boot follows
+// wiring code, phases declared by InitPhases go first. It stops once ctx is
+// done. Failed Init rolls back: entities built by it are cleaned up in reverse
+// order and could be built again, so boot doesn't leave dangling listeners
+// and connections.
+func (c *Container) Init(ctx context.Context) error {
+	c.root().beginTransition()
+	defer c.root().endTransition()
+
+	c.mu.RLock()
+	mark := len(c.cleanup)
+	c.mu.RUnlock()
+
+	// entities which aren't built yet are the ones Init may build or fail
+	unbuilt := c.unbuilt()
+
+	eager := c.query(func(e Entity) bool { return e.Eager })
+	phases := c.root().initPhases
+	slices.SortStableFunc(eager, func(a, b Entity) int {
+		if byPhase := phaseIndex(phases, a.initPhase) - phaseIndex(phases, b.initPhase); byPhase != 0 {
+			return byPhase
+		}
+
+		return cmp.Compare(a.seq, b.seq)
+	})
+
+	for _, e := range eager {
+		if err := ctx.Err(); err != nil {
+			c.record(StartError, e, fmt.Errorf("init stopped before %v: %w", e, err))
+			return c.rollback(mark, unbuilt, err)
+		}
+
+		if _, err := e.Get(); err != nil {
+			c.record(StartError, e, err)
+			return c.rollback(mark, unbuilt, err)
+		}
+	}
+
+	return nil
+}
+
+// rollback cleans up instances pushed since mark and resets entities which
+// were unbuilt before Init, the built ones and the failed one with its
+// remembered error alike, so none of them keeps instance of cleaned up
+// dependency
+func (c *Container) rollback(mark int, unbuilt []entity, cause error) error {
+	c.mu.Lock()
+	pending := slices.Clone(c.cleanup[min(mark, len(c.cleanup)):])
+	c.cleanup = c.cleanup[:min(mark, len(c.cleanup))]
+	c.mu.Unlock()
+
+	built := 0
+	for _, entity := range unbuilt {
+		if entity.info().Built {
+			built++
+		}
+	}
+
+	errs := []error{}
+	for _, cleanup := range c.cleanupOrder(pending) {
+		_, err := c.runCleanup(cleanup)
+		errs = append(errs, err)
+		cleanup.source.reset()
+	}
+
+	for _, entity := range unbuilt {
+		entity.reset()
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%w (rollback failed: %w)", cause, err)
+	}
+
+	return fmt.Errorf("%w (rolled back %d entities)", cause, built)
+}
+
+// unbuilt entities resolvable through c
+func (c *Container) unbuilt() []entity {
+	seen := make(map[string]struct{})
+	result := []entity{}
+
+	for owner := c; owner != nil; owner = owner.parent {
+		owner.mu.RLock()
+		for key, entity := range owner.entities {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			if !entity.info().Built {
+				result = append(result, entity)
+			}
+		}
+		owner.mu.RUnlock()
+	}
+
+	return result
+}
+
+// Restart does Cleanup, drops cached instances and recorded errors and builds
+// eager entities again from retained registrations
+func (c *Container) Restart(ctx context.Context) error {
+	cleanupErr := c.Cleanup()
+
+	c.mu.RLock()
+	entities := maps.Clone(c.entities)
+	c.mu.RUnlock()
+
+	for _, entity := range entities {
+		entity.reset()
+	}
+
+	c.errs.reset()
+	c.resetFailures()
+	c.mu.Lock()
+	c.failed = nil
+	c.mu.Unlock()
+
+	return errors.Join(cleanupErr, c.Init(ctx))
+}
+
+// Warm builds named entity and caches it without retrieving it, e.g. to
+// pre-warm caches and connections from warmup routine
+func Warm[T any](c *Container, name string) error {
+	_, err := TryGetNamed[T](c, name)
+	return err
+}
@@ -0,0 +1,243 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestInitRestart(t *testing.T) {
+	var (
+		c       = di.New()
+		built   = map[string]int{}
+		cleaned = 0
+	)
+
+	for _, name := range []string{"eager", "lazy"} {
+		di.SetNamed(c, name, di.OptSetup(func() (string, error) {
+			built[name]++
+			return name, nil
+		}), di.OptCleanup(func(string) error {
+			cleaned++
+			return nil
+		}))
+	}
+	di.SetNamed(c, "eager", di.OptEager[string]())
+
+	if err := c.Init(context.Background()); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	di.GetNamed[string](c, "lazy")
+
+	if err := c.Restart(context.Background()); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if built["eager"] != 2 || built["lazy"] != 1 || cleaned != 2 {
+		t.Errorf("Unexpected: %v, %v", built, cleaned)
+	}
+
+	di.GetNamed[string](c, "lazy")
+	if built["lazy"] != 2 {
+		t.Errorf("Unexpected: %v", built)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := c.Restart(ctx); err == nil {
+		t.Errorf("Restart should fail on canceled context")
+	}
+}
+
+func TestWarm(t *testing.T) {
+	var (
+		c       = di.New()
+		built   = 0
+		cleaned = 0
+	)
+
+	di.SetNamed(c, "pool", di.OptSetup(func() (int, error) {
+		built++
+		return 10, nil
+	}), di.OptMiddleware(func(val int) (int, error) {
+		return val * 2, nil
+	}), di.OptCleanup(func(int) error {
+		cleaned++
+		return nil
+	}))
+
+	if err := di.Warm[int](c, "pool"); err != nil || built != 1 {
+		t.Errorf("Unexpected: %v, %v", err, built)
+	}
+
+	if val := di.GetNamed[int](c, "pool"); val != 20 || built != 1 {
+		t.Errorf("Unexpected: %v, %v", val, built)
+	}
+
+	if err := c.Cleanup(); err != nil || cleaned != 1 {
+		t.Errorf("Unexpected: %v, %v", err, cleaned)
+	}
+
+	if err := di.Warm[string](c, ""); err == nil {
+		t.Errorf("Error expected")
+	}
+}
+
+func TestInitOrder(t *testing.T) {
+	for range 10 {
+		var (
+			c     = di.New()
+			order = []string{}
+		)
+
+		for _, name := range []string{"db", "cache", "server", "broker"} {
+			di.SetNamed(c, name, di.OptSetup(func() (string, error) {
+				order = append(order, name)
+				return name, nil
+			}), di.OptEager[string]())
+		}
+
+		if err := c.Init(context.Background()); err != nil {
+			t.Errorf("Unexpected: %v", err)
+		}
+
+		if got := strings.Join(order, ","); got != "db,cache,server,broker" {
+			t.Fatalf("Unexpected: %v", got)
+		}
+	}
+}
+
+func TestInitRollback(t *testing.T) {
+	var (
+		c       = di.New()
+		errTest = errors.New("test")
+		fail    = true
+		order   = []string{}
+	)
+
+	for _, name := range []string{"db", "cache", "server"} {
+		di.SetNamed(c, name, di.OptSetup(func() (string, error) {
+			if name == "server" && fail {
+				return "", errTest
+			}
+
+			return name, nil
+		}), di.OptCleanup(func(string) error {
+			order = append(order, name)
+			return nil
+		}), di.OptEager[string]())
+	}
+
+	err := c.Init(context.Background())
+	if !errors.Is(err, errTest) || !strings.Contains(err.Error(), "rolled back 2 entities") {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if got := strings.Join(order, ","); got != "cache,db" {
+		t.Errorf("Unexpected: %v", got)
+	}
+
+	fail = false
+	if err := c.Init(context.Background()); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	// failed Init is recorded
+	if err := c.Cleanup(); !errors.Is(err, errTest) || len(c.Errors(di.CleanupError)) != 0 {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if got := strings.Join(order, ","); got != "cache,db,server,cache,db" {
+		t.Errorf("Unexpected: %v", got)
+	}
+}
+
+func TestInitRollbackDependents(t *testing.T) {
+	type (
+		db   struct{ closed bool }
+		repo struct{ db *db }
+	)
+
+	var (
+		c    = di.New()
+		fail = true
+	)
+
+	di.Set(c, di.OptSetup(func() (*db, error) {
+		return &db{}, nil
+	}), di.OptCleanup(func(d *db) error {
+		d.closed = true
+		return nil
+	}), di.OptEager[*db]())
+	// repo has no cleanup of its own
+	di.Set(c, di.OptSetupC(func(c *di.Container) (*repo, error) {
+		return &repo{db: di.Get[*db](c)}, nil
+	}), di.OptEager[*repo]())
+	di.Set(c, di.OptSetup(func() (string, error) {
+		if fail {
+			return "", errors.New("test")
+		}
+
+		return "server", nil
+	}), di.OptEager[string]())
+
+	if err := c.Init(context.Background()); err == nil || !strings.Contains(err.Error(), "rolled back 2 entities") {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if r := di.Get[*repo](c); r.db.closed || r.db != di.Get[*db](c) {
+		t.Errorf("Repo should be built again with new db")
+	}
+
+	fail = false
+	if err := c.Init(context.Background()); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
+
+func TestOptDrain(t *testing.T) {
+	type (
+		queue  struct{}
+		server struct{}
+		ctxKey struct{}
+	)
+
+	var (
+		c   = di.New()
+		log = []string{}
+		ctx = context.WithValue(context.Background(), ctxKey{}, "drain ctx")
+	)
+
+	di.Set(c, di.OptSetup(func() (*queue, error) {
+		return &queue{}, nil
+	}), di.OptDrain(func(context.Context, *queue) error {
+		log = append(log, "flush queue")
+		return nil
+	}), di.OptCleanup(func(*queue) error {
+		log = append(log, "close queue")
+		return nil
+	}))
+	di.Set(c, di.OptSetupC(func(c *di.Container) (*server, error) {
+		di.Get[*queue](c)
+		return &server{}, nil
+	}), di.OptDrain(func(ctx context.Context, _ *server) error {
+		log = append(log, fmt.Sprint("stop accepting by ", ctx.Value(ctxKey{})))
+		return nil
+	}))
+
+	di.Get[*server](c)
+
+	if err := c.CleanupContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(log) != "[flush queue stop accepting by drain ctx close queue]" {
+		t.Errorf("Unexpected: %v", log)
+	}
+}
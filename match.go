@@ -0,0 +1,46 @@
+package di
+
+import (
+	"path"
+	"reflect"
+)
+
+// GetNamedMatch returns all entities of type T whose names match pattern,
+// sorted by name. Pattern syntax is the one of path.Match, e.g. "shard-*".
+func GetNamedMatch[T any](c *Container, pattern string) []T {
+	if _, err := path.Match(pattern, ""); err != nil {
+		c.fail(entityOf[T](c, pattern), err)
+	}
+
+	pattern = c.named(pattern)
+	entities := c.query(func(e Entity) bool {
+		ok, _ := path.Match(pattern, e.Name)
+		return ok && e.Type == reflect.TypeFor[T]()
+	})
+
+	result := make([]T, 0, len(entities))
+	for _, e := range entities {
+		val, err := c.resolve(e.key)
+		if err != nil {
+			c.fail(e, err)
+		}
+
+		result = append(result, valueOf[T](val))
+	}
+
+	return result
+}
+
+// ListNamed returns sorted names of entities of type T without building them
+func ListNamed[T any](c *Container) []string {
+	entities := c.query(func(e Entity) bool { return e.Type == reflect.TypeFor[T]() })
+
+	result := make([]string, 0, len(entities))
+	for _, e := range entities {
+		if name, ok := c.unnamed(e.Name); ok {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
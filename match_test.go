@@ -0,0 +1,62 @@
+package di_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestGetNamedMatch(t *testing.T) {
+	type shard string
+
+	c := di.New()
+
+	for _, name := range []string{"shard-2", "shard-1", "replica"} {
+		di.SetNamed(c, name, di.OptSetup(func() (shard, error) {
+			return shard(name), nil
+		}))
+	}
+	di.SetNamed(c, "shard-3", di.OptSetup(func() (string, error) {
+		panic("no need to init 'string'")
+	}))
+
+	if val := di.GetNamedMatch[shard](c, "shard-*"); fmt.Sprint(val) != "[shard-1 shard-2]" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if val := di.GetNamedMatch[shard](c, "none-*"); len(val) != 0 {
+		t.Errorf("Unexpected: %v", val)
+	}
+}
+
+func TestListNamed(t *testing.T) {
+	type db string
+
+	var (
+		c       = di.New()
+		billing = c.Namespace("billing")
+	)
+
+	di.Set(c, di.OptSetup(func() (db, error) {
+		panic("no need to init 'db'")
+	}))
+	di.SetNamed(c, "replica", di.OptSetup(func() (db, error) {
+		panic("no need to init 'db'")
+	}))
+	di.SetNamed(billing, "primary", di.OptSetup(func() (db, error) {
+		panic("no need to init 'db'")
+	}))
+
+	if val := di.ListNamed[db](c); fmt.Sprintf("%q", val) != `["" "billing/primary" "replica"]` {
+		t.Errorf("Unexpected: %q", val)
+	}
+
+	if val := di.ListNamed[db](billing); fmt.Sprintf("%q", val) != `["primary"]` {
+		t.Errorf("Unexpected: %q", val)
+	}
+
+	if val := di.ListNamed[string](c); len(val) != 0 {
+		t.Errorf("Unexpected: %q", val)
+	}
+}
@@ -0,0 +1,60 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// OptCallMethods calls methods of built value by names with arguments got
+// from container as Provide does, e.g. SetLogger of third-party type whose
+// constructor can't take it. Method may return error, which fails setup.
+// Methods are called by middleware, so the value returned by the ones added
+// earlier is the one they're called on.
+func OptCallMethods[T any](names ...string) func(*entityImpl[T]) {
+	site := callerSite()
+
+	return func(s *entityImpl[T]) {
+		s.middlewares = append(s.middlewares, middleware[T]{
+			site: site,
+			fn: func(c *Container, val T) (T, error) {
+				for _, name := range names {
+					if err := c.callMethod(reflect.ValueOf(&val).Elem(), name); err != nil {
+						return empty[T](), err
+					}
+				}
+
+				return val, nil
+			},
+		})
+	}
+}
+
+// callMethod of v by name, the pointer receiver one is found if v is
+// addressable
+func (c *Container) callMethod(v reflect.Value, name string) error {
+	method := v.MethodByName(name)
+	if !method.IsValid() && v.Kind() == reflect.Interface && !v.IsNil() {
+		method = v.Elem().MethodByName(name)
+	}
+
+	if !method.IsValid() && v.CanAddr() {
+		method = v.Addr().MethodByName(name)
+	}
+
+	if !method.IsValid() {
+		return fmt.Errorf("method %s of %v not found", name, v.Type())
+	}
+
+	results, err := c.call(method)
+	if err != nil {
+		return fmt.Errorf("method %s: %w", name, err)
+	}
+
+	if n := len(results); n > 0 && method.Type().Out(n-1) == errorType {
+		if err, _ := results[n-1].Interface().(error); err != nil {
+			return fmt.Errorf("method %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package di_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+type thirdParty struct {
+	db     *providedDB
+	prefix string
+}
+
+func (t *thirdParty) SetDB(db *providedDB) { t.db = db }
+
+func (t *thirdParty) SetPrefix(prefix string) error {
+	if prefix == "" {
+		return errors.New("empty prefix")
+	}
+
+	t.prefix = prefix
+
+	return nil
+}
+
+func TestOptCallMethods(t *testing.T) {
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (*providedDB, error) { return &providedDB{dsn: "dsn"}, nil }))
+	di.Set(c, di.OptSetup(func() (string, error) { return "app", nil }))
+	di.Set(c, di.OptSetup(func() (*thirdParty, error) {
+		return &thirdParty{}, nil
+	}), di.OptCallMethods[*thirdParty]("SetDB", "SetPrefix"))
+	di.Set(c, di.OptSetup(func() (thirdParty, error) {
+		return thirdParty{}, nil
+	}), di.OptCallMethods[thirdParty]("SetDB"))
+
+	if val := di.Get[*thirdParty](c); val.db.dsn != "dsn" || val.prefix != "app" {
+		t.Errorf("Unexpected: %+v", val)
+	}
+
+	if val := di.Get[thirdParty](c); val.db == nil {
+		t.Errorf("Unexpected: %+v", val)
+	}
+}
+
+func TestOptCallMethodsErrors(t *testing.T) {
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (string, error) { return "", nil }))
+	di.Set(c, di.OptSetup(func() (*thirdParty, error) {
+		return &thirdParty{}, nil
+	}), di.OptCallMethods[*thirdParty]("SetPrefix"))
+	di.Set(c, di.OptSetup(func() (thirdParty, error) {
+		return thirdParty{}, nil
+	}), di.OptCallMethods[thirdParty]("SetLogger"))
+
+	if _, err := di.TryGet[*thirdParty](c); err == nil {
+		t.Errorf("TryGet should fail on method error")
+	}
+
+	if _, err := di.TryGet[thirdParty](c); err == nil {
+		t.Errorf("TryGet should fail on missing method")
+	}
+}
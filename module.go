@@ -0,0 +1,77 @@
+package di
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	modulesMu sync.RWMutex
+	modules   = map[string]Module{}
+)
+
+// Module is reusable wiring bundle, constructor of module could take typed
+// configuration, e.g.
+//
+//	func NewStorageModule(cfg StorageConfig) di.Module {
+//		return di.NewModule("storage", func(c *di.Container) {
+//			di.Set(c, di.OptSetup(func() (*Storage, error) { return OpenStorage(cfg) }))
+//		})
+//	}
+type Module struct {
+	Name     string
+	register func(*Container)
+}
+
+func NewModule(name string, register func(*Container)) Module {
+	return Module{Name: name, register: register}
+}
+
+// Install registrations of module into container, method value fits
+// Builder.Add
+func (m Module) Install(c *Container) {
+	m.register(c)
+}
+
+// Entities module contributes, they're registered into throwaway container
+// to be described, so setups aren't called
+func (m Module) Entities() []Entity {
+	c := New()
+	m.register(c)
+
+	return c.Entities()
+}
+
+func (m Module) String() string { return m.Name }
+
+// RegisterModule makes module discoverable, it's meant to be called from init
+// of feature package, so application assembles container from packages it
+// imports, e.g. blank ones. It panics if module with the same name is
+// registered already.
+func RegisterModule(m Module) {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+
+	if _, ok := modules[m.Name]; ok {
+		panic("di: RegisterModule called twice for module " + m.Name)
+	}
+
+	modules[m.Name] = m
+}
+
+// DiscoveredModules returns modules registered by RegisterModule sorted by
+// name, e.g. di.NewBuilder().Add(m.Install) for each of them
+func DiscoveredModules() []Module {
+	modulesMu.RLock()
+	defer modulesMu.RUnlock()
+
+	result := make([]Module, 0, len(modules))
+	for _, m := range modules {
+		result = append(result, m)
+	}
+
+	slices.SortFunc(result, func(a, b Module) int { return strings.Compare(a.Name, b.Name) })
+
+	return result
+}
@@ -0,0 +1,78 @@
+package di_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+type (
+	storageConfig struct{ DSN string }
+	storage       struct{ dsn string }
+)
+
+func init() {
+	di.RegisterModule(newStorageModule(storageConfig{DSN: "discovered"}))
+}
+
+func newStorageModule(cfg storageConfig) di.Module {
+	return di.NewModule("storage", func(c *di.Container) {
+		di.Set(c, di.OptSetup(func() (*storage, error) {
+			return &storage{dsn: cfg.DSN}, nil
+		}))
+		di.SetNamed(c, "dsn", di.OptSetup(func() (string, error) {
+			return cfg.DSN, nil
+		}))
+	})
+}
+
+func TestModule(t *testing.T) {
+	var (
+		primary = newStorageModule(storageConfig{DSN: "primary"})
+		replica = newStorageModule(storageConfig{DSN: "replica"})
+	)
+
+	c := di.New()
+	primary.Install(c)
+	replica.Install(c.Namespace("replica"))
+
+	if val := di.Get[*storage](c); val.dsn != "primary" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if val := di.Get[*storage](c.Namespace("replica")); val.dsn != "replica" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if entities := fmt.Sprint(primary.Entities()); entities != "[<*di_test.storage> dsn<string>]" {
+		t.Errorf("Unexpected: %v", entities)
+	}
+
+	if _, err := di.NewBuilder().Add(primary.Install).Build(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
+
+func TestDiscoveredModules(t *testing.T) {
+	modules := di.DiscoveredModules()
+	if fmt.Sprint(modules) != "[storage]" {
+		t.Fatalf("Unexpected: %v", modules)
+	}
+
+	c := di.New()
+	for _, m := range modules {
+		m.Install(c)
+	}
+
+	if val := di.Get[*storage](c); val.dsn != "discovered" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("RegisterModule should panic on duplicate")
+		}
+	}()
+	di.RegisterModule(newStorageModule(storageConfig{}))
+}
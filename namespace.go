@@ -0,0 +1,31 @@
+package di
+
+import "strings"
+
+// Namespace returns view of container which prefixes names of entities set
+// and got through it, so independent packages could register the same types
+// without coordinating names. Namespaces could be nested. Entity named
+// "primary" in namespace "billing" is "billing/primary" outside of it, the
+// unnamed one is "billing/", so it doesn't collide with entity named
+// "billing".
+func (c *Container) Namespace(name string) *Container {
+	return &Container{state: c.state, namespace: c.named(name)}
+}
+
+func (c *Container) named(name string) string {
+	switch {
+	case c.namespace == "":
+		return name
+	}
+
+	return c.namespace + "/" + name
+}
+
+// unnamed is the opposite of named, reports whether name is in namespace
+func (c *Container) unnamed(name string) (string, bool) {
+	if c.namespace == "" {
+		return name, true
+	}
+
+	return strings.CutPrefix(name, c.namespace+"/")
+}
@@ -0,0 +1,64 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestNamespace(t *testing.T) {
+	type db string
+
+	var (
+		c        = di.New()
+		billing  = c.Namespace("billing")
+		shipping = c.Namespace("shipping")
+	)
+
+	di.SetNamed(billing, "primary", di.OptSetup(func() (db, error) {
+		return "billing", nil
+	}))
+	di.SetNamed(shipping, "primary", di.OptSetup(func() (db, error) {
+		return "shipping", nil
+	}))
+
+	if val := di.GetNamed[db](billing, "primary"); val != "billing" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if val := di.GetNamed[db](shipping, "primary"); val != "shipping" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if val := di.GetNamed[db](c, "billing/primary"); val != "billing" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if _, err := di.TryGetNamed[db](c, "primary"); err == nil {
+		t.Errorf("Namespaced entity shouldn't be visible without prefix")
+	}
+
+	di.Set(billing.Namespace("eu"), di.OptSetup(func() (db, error) {
+		return "billing-eu", nil
+	}))
+
+	if val := di.GetNamed[db](c, "billing/eu/"); val != "billing-eu" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	// unnamed entity of namespace doesn't replace the one named after it
+	di.SetNamed(c, "billing", di.OptSetup(func() (db, error) {
+		return "root", nil
+	}))
+	di.Set(billing, di.OptSetup(func() (db, error) {
+		return "billing-default", nil
+	}))
+
+	if val := di.GetNamed[db](c, "billing"); val != "root" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if val := di.Get[db](billing); val != "billing-default" {
+		t.Errorf("Unexpected: %v", val)
+	}
+}
@@ -0,0 +1,47 @@
+package di
+
+import "reflect"
+
+// OnNotFound sets provider of entities which aren't registered, e.g. to
+// fill them with fakes in tests. Provided value is registered as entity
+// instance, provider reports false if it has none. Scope without provider
+// of its own uses the one of its parent.
+func (c *Container) OnNotFound(provide func(Entity) (any, bool)) {
+	c.notFound = provide
+}
+
+// notFoundProvider of c or its closest parent having one
+func (c *Container) notFoundProvider() func(Entity) (any, bool) {
+	for owner := c; owner != nil; owner = owner.parent {
+		if owner.notFound != nil {
+			return owner.notFound
+		}
+	}
+
+	return nil
+}
+
+// provideNotFound registers entity of type typ provided by OnNotFound
+// provider, as Provide does
+func (c *Container) provideNotFound(typ reflect.Type, name string) {
+	val, ok := c.notFoundProvider()(Entity{Name: c.named(name), Type: typ})
+	if !ok || (val != nil && !reflect.TypeOf(val).AssignableTo(typ)) {
+		return
+	}
+
+	entityName := keyFor(typ, c.named(name))
+	c.checkRegistration(entityName)
+
+	entity := &entityImpl[any]{
+		typ:     typ,
+		name:    c.named(name),
+		site:    callerSite(),
+		seq:     c.root().nextSeq(),
+		inst:    new(instance[any]),
+		setupFn: func(*Container) (any, func(), error) { return val, nil, nil },
+	}
+
+	c.mu.Lock()
+	c.put(entityName, entity)
+	c.mu.Unlock()
+}
@@ -0,0 +1,34 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestOnNotFound(t *testing.T) {
+	var (
+		c        = di.New()
+		provided = []string{}
+	)
+
+	c.OnNotFound(func(e di.Entity) (any, bool) {
+		provided = append(provided, e.String())
+		return "fake", e.Name == "fake"
+	})
+
+	s := c.BeginScope()
+	if val := di.GetNamed[string](s.Container, "fake"); val != "fake" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	di.GetNamed[string](s.Container, "fake")
+
+	if _, err := di.TryGetNamed[string](c, "real"); err == nil {
+		t.Errorf("Error expected")
+	}
+
+	if len(provided) != 2 || provided[0] != "fake<string>" {
+		t.Errorf("Unexpected: %v", provided)
+	}
+}
@@ -0,0 +1,64 @@
+package di
+
+import "slices"
+
+// ShutdownPhases declares order in which Cleanup deinitializes entities
+// assigned to phases by OptShutdownPhase, e.g. "servers", "workers", "stores",
+// regardless of order they were set up in. Entities without phase are
+// cleaned up after all phases, unless "" is declared as a phase.
+func (c *Container) ShutdownPhases(phases ...string) {
+	c.phases = phases
+}
+
+// OptShutdownPhase assigns entity to phase declared by ShutdownPhases
+func OptShutdownPhase[T any](phase string) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.phase = phase }
+}
+
+// OptCleanupPriority nudges entity "destructor" earlier (positive priority)
+// or later (negative one) within its shutdown phase, default priority is 0
+func OptCleanupPriority[T any](priority int) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.priority = priority }
+}
+
+// InitPhases declares order in which Init builds eager entities assigned to
+// phases by OptInitPhase, e.g. "infrastructure", "migrations", "services",
+// "servers", regardless of order they were registered in. Eager entities
+// without phase are built after all phases, unless "" is declared as a phase.
+func (c *Container) InitPhases(phases ...string) {
+	c.initPhases = phases
+}
+
+// OptInitPhase assigns entity to phase declared by InitPhases and makes it
+// eager
+func OptInitPhase[T any](phase string) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.eager, s.initPhase = true, phase }
+}
+
+// cleanupOrder of pending cleanups is reversed setup order sorted by
+// shutdown phases and priorities
+func (c *Container) cleanupOrder(pending []cleanup) []cleanup {
+	var (
+		phases = c.root().phases
+		order  = slices.Clone(pending)
+	)
+
+	slices.Reverse(order)
+	slices.SortStableFunc(order, func(a, b cleanup) int {
+		if byPhase := phaseIndex(phases, a.phase) - phaseIndex(phases, b.phase); byPhase != 0 {
+			return byPhase
+		}
+
+		return b.priority - a.priority
+	})
+
+	return order
+}
+
+func phaseIndex(phases []string, phase string) int {
+	if i := slices.Index(phases, phase); i >= 0 {
+		return i
+	}
+
+	return len(phases)
+}
@@ -0,0 +1,130 @@
+package di_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestShutdownPhases(t *testing.T) {
+	type (
+		store  string
+		worker string
+		server string
+		cache  string
+	)
+
+	var (
+		c   = di.New()
+		log = []string{}
+	)
+
+	cleanup := func(name string) error {
+		log = append(log, name)
+		return nil
+	}
+
+	c.ShutdownPhases("servers", "workers", "stores")
+
+	di.Set(c, di.OptSetup(func() (server, error) {
+		return "server", nil
+	}), di.OptCleanup(func(server) error { return cleanup("server") }),
+		di.OptShutdownPhase[server]("servers"))
+	di.Set(c, di.OptSetup(func() (worker, error) {
+		di.Get[server](c) // weird, but order is defined by phases anyway
+		return "worker", nil
+	}), di.OptCleanup(func(worker) error { return cleanup("worker") }),
+		di.OptShutdownPhase[worker]("workers"))
+	di.Set(c, di.OptSetup(func() (cache, error) {
+		return "cache", nil
+	}), di.OptCleanup(func(cache) error { return cleanup("cache") }))
+	di.Set(c, di.OptSetup(func() (store, error) {
+		return "store", nil
+	}), di.OptCleanup(func(store) error { return cleanup("store") }),
+		di.OptShutdownPhase[store]("stores"))
+
+	di.Get[store](c)
+	di.Get[cache](c)
+	di.Get[worker](c)
+
+	if err := c.Cleanup(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if fmt.Sprint(log) != "[server worker store cache]" {
+		t.Errorf("Unexpected: %v", log)
+	}
+}
+
+func TestCleanupPriority(t *testing.T) {
+	var (
+		c   = di.New()
+		log = []string{}
+	)
+
+	for _, name := range []string{"telemetry", "db", "server"} {
+		priority := 0
+		if name == "telemetry" {
+			priority = -1
+		}
+
+		di.SetNamed(c, name, di.OptSetup(func() (string, error) {
+			return name, nil
+		}), di.OptCleanup(func(s string) error {
+			log = append(log, s)
+			return nil
+		}), di.OptCleanupPriority[string](priority))
+		di.GetNamed[string](c, name)
+	}
+
+	if err := c.Cleanup(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if fmt.Sprint(log) != "[server db telemetry]" {
+		t.Errorf("Unexpected: %v", log)
+	}
+}
+
+func TestInitPhases(t *testing.T) {
+	type (
+		db     struct{}
+		schema struct{}
+		api    struct{}
+		cron   struct{}
+	)
+
+	var (
+		c   = di.New()
+		log = []string{}
+	)
+
+	c.InitPhases("infrastructure", "migrations", "servers")
+
+	di.Set(c, di.OptSetup(func() (cron, error) {
+		log = append(log, "cron")
+		return cron{}, nil
+	}), di.OptEager[cron]())
+	di.Set(c, di.OptSetup(func() (api, error) {
+		log = append(log, "api")
+		return api{}, nil
+	}), di.OptInitPhase[api]("servers"))
+	di.Set(c, di.OptSetup(func() (schema, error) {
+		log = append(log, "schema")
+		return schema{}, nil
+	}), di.OptInitPhase[schema]("migrations"))
+	di.Set(c, di.OptSetup(func() (db, error) {
+		log = append(log, "db")
+		return db{}, nil
+	}), di.OptInitPhase[db]("infrastructure"))
+
+	if err := c.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(log) != "[db schema api cron]" {
+		t.Errorf("Unexpected: %v", log)
+	}
+}
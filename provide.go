@@ -0,0 +1,329 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+var (
+	containerType = reflect.TypeFor[*Container]()
+	errorType     = reflect.TypeFor[error]()
+	cleanupType   = reflect.TypeFor[func()]()
+)
+
+// Provide registers results of each of constructors under their own types,
+// e.g. func(cfg Config) (*sql.DB, *redis.Client, error), so one constructor
+// wires family of related entities. The last result may be error, func()
+// result is cleanup, as google/wire providers return, which runs once all of
+// results built by the call are cleaned up. Parameters are got by their
+// types, *Container one is the container resolving. Variadic parameter gets
+// group of all entities of its element type sorted by name, e.g.
+// func(handlers ...Handler) Router, so plugins could contribute to it by
+// SetNamed under their own names. Struct parameter embedding In gets its
+// fields instead, see In. Constructor is called once for all of results and
+// called again once any of them is built again, e.g. after Reset. Set of
+// providers, e.g. []any{NewDB, NewRepo} kept next to wire.NewSet, is
+// registered at once by Provide(c, set...).
+func Provide(c *Container, constructors ...any) error {
+	errs := make([]error, 0, len(constructors))
+	for _, constructor := range constructors {
+		errs = append(errs, ProvideNamed(c, "", constructor))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ProvideNamed registers results of constructor under name, parameters are
+// got as by Get
+func ProvideNamed(c *Container, name string, constructor any) error {
+	fn := reflect.ValueOf(constructor)
+	if fn.Kind() != reflect.Func {
+		return fmt.Errorf("provide: %T is not a function", constructor)
+	}
+
+	call := &providerCall{fn: fn, cleanup: -1}
+	for i := range fn.Type().NumOut() {
+		result := fn.Type().Out(i)
+		switch {
+		case result == errorType && i == fn.Type().NumOut()-1:
+			call.hasErr = true
+			continue
+		case result == cleanupType && call.cleanup < 0:
+			call.cleanup = i
+			continue
+		case slices.Contains(call.results, result):
+			return fmt.Errorf("provide: %v returns %v twice", fn.Type(), result)
+		}
+
+		call.results = append(call.results, result)
+		call.outs = append(call.outs, i)
+	}
+
+	if len(call.results) == 0 {
+		return fmt.Errorf("provide: %v has no results", fn.Type())
+	}
+
+	params := c.params(fn.Type())
+	site := callerSite()
+	for i, result := range call.results {
+		entityName := keyFor(result, c.named(name))
+		c.checkRegistration(entityName)
+
+		entity := &entityImpl[any]{
+			typ:     result,
+			name:    c.named(name),
+			site:    site,
+			seq:     c.root().nextSeq(),
+			params:  params,
+			inst:    new(instance[any]),
+			setupFn: func(c *Container) (any, func(), error) { return call.result(c, i) },
+		}
+
+		c.mu.Lock()
+		c.put(entityName, entity)
+		c.mu.Unlock()
+	}
+
+	return nil
+}
+
+// providerCall shares results of constructor call between its entities
+type providerCall struct {
+	fn      reflect.Value
+	results []reflect.Type
+	// outs are indexes of results among constructor outputs
+	outs    []int
+	cleanup int
+	hasErr  bool
+
+	mu   sync.Mutex
+	last *providerResults
+}
+
+// providerResults of single constructor call
+type providerResults struct {
+	values  []reflect.Value
+	cleanup func()
+	// taken results, entity built again calls constructor again
+	taken []bool
+	// pending cleanups of taken results
+	pending int
+}
+
+func (p *providerCall) result(c *Container, i int) (any, func(), error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.last == nil || p.last.taken[i] {
+		values, err := c.call(p.fn)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if p.hasErr {
+			if err, _ := values[len(values)-1].Interface().(error); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		p.last = &providerResults{values: values, taken: make([]bool, len(p.results))}
+		if p.cleanup >= 0 {
+			p.last.cleanup, _ = values[p.cleanup].Interface().(func())
+		}
+	}
+
+	results := p.last
+	results.taken[i] = true
+	results.pending++
+
+	return results.values[p.outs[i]].Interface(), p.release(results), nil
+}
+
+// release returns cleanup of taken result, the last one cleans up the call
+func (p *providerCall) release(results *providerResults) func() {
+	if results.cleanup == nil {
+		return nil
+	}
+
+	return func() {
+		p.mu.Lock()
+		results.pending--
+		done := results.pending == 0
+		if done && p.last == results {
+			// results not taken yet mustn't outlive cleanup
+			p.last = nil
+		}
+		p.mu.Unlock()
+
+		if done {
+			results.cleanup()
+		}
+	}
+}
+
+// typed returns e as *entityImpl[T], the one registered by Provide, whose T
+// is any, is copied along with its instance
+func typed[T any](e entity) *entityImpl[T] {
+	if entity, ok := e.(*entityImpl[T]); ok {
+		return entity
+	}
+
+	provided := e.(*entityImpl[any])
+	entity := &entityImpl[T]{
+		name:   provided.name,
+		site:   provided.site,
+		seq:    provided.seq,
+		params: provided.params,
+		inst:   new(instance[T]),
+		setupFn: func(c *Container) (T, func(), error) {
+			val, release, err := provided.setupFn(c)
+			return valueOf[T](val), release, err
+		},
+	}
+
+	provided.inst.mu.Lock()
+	entity.inst.val, entity.inst.err = valueOf[T](provided.inst.val), provided.inst.err
+	entity.inst.resolution = provided.inst.resolution
+	entity.inst.built.Store(provided.inst.built.Load())
+	provided.inst.mu.Unlock()
+
+	return entity
+}
+
+// retype registration of c found under entityName to *entityImpl[T], pending
+// cleanups of the instance registered by Provide are handed over to the copy
+func retype[T any](c *Container, entityName string, found entity) *entityImpl[T] {
+	entity := typed[T](found)
+	if entity == found {
+		return entity
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.put(entityName, entity)
+	for i := range c.cleanup {
+		if c.cleanup[i].source == found {
+			c.cleanup[i].source = entity
+		}
+	}
+
+	return entity
+}
+
+// call fn with arguments got from container, see Provide
+func (c *Container) call(fn reflect.Value) ([]reflect.Value, error) {
+	args, err := c.args(fn.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	if fn.Type().IsVariadic() {
+		return fn.CallSlice(args), nil
+	}
+
+	return fn.Call(args), nil
+}
+
+// args of function typ got from container
+func (c *Container) args(typ reflect.Type) ([]reflect.Value, error) {
+	args := make([]reflect.Value, typ.NumIn())
+
+	for i := range args {
+		param := typ.In(i)
+		switch {
+		case param == containerType:
+			args[i] = reflect.ValueOf(c)
+			continue
+		case param == selfType:
+			args[i] = reflect.ValueOf(c.self())
+			continue
+		case typ.IsVariadic() && i == len(args)-1:
+			group, err := c.group(param.Elem())
+			if err != nil {
+				return nil, err
+			}
+
+			args[i] = group
+			continue
+		case isIn(param):
+			args[i] = reflect.New(param).Elem()
+			if err := c.fill(args[i], false); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		val, err := c.resolveType(param, "")
+		if err != nil {
+			return nil, err
+		}
+
+		args[i] = reflect.New(param).Elem()
+		if val != nil {
+			args[i].Set(reflect.ValueOf(val))
+		}
+	}
+
+	return args, nil
+}
+
+// params of function typ which args gets from container by their types,
+// including required fields of In structs, malformed tags are left for
+// constructor call to report
+func (c *Container) params(typ reflect.Type) []Dependency {
+	params := []Dependency{}
+	dep := func(typ reflect.Type, name string) {
+		params = append(params, Dependency{Name: c.named(name), Type: typ, key: keyFor(typ, c.named(name))})
+	}
+
+	for i := range typ.NumIn() {
+		param := typ.In(i)
+		switch {
+		case param == containerType, param == selfType, typ.IsVariadic() && i == typ.NumIn()-1:
+			continue
+		case !isIn(param):
+			dep(param, "")
+			continue
+		}
+
+		for j := range param.NumField() {
+			f := param.Field(j)
+			if f.Type == inType || !f.IsExported() {
+				continue
+			}
+
+			if tag, err := parseFieldTag(f); err == nil && !tag.skip && !tag.optional {
+				dep(f.Type, tag.name)
+			}
+		}
+	}
+
+	return params
+}
+
+// group of entities of type typ visible from c sorted by name, as slice
+func (c *Container) group(typ reflect.Type) (reflect.Value, error) {
+	entities := c.query(func(e Entity) bool {
+		_, ok := c.unnamed(e.Name)
+		return ok && e.Type == typ
+	})
+
+	group := reflect.MakeSlice(reflect.SliceOf(typ), len(entities), len(entities))
+	for i, e := range entities {
+		val, err := c.resolve(e.key)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		if val != nil {
+			group.Index(i).Set(reflect.ValueOf(val))
+		}
+	}
+
+	return group, nil
+}
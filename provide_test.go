@@ -0,0 +1,280 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+type (
+	providedConfig struct{ dsn string }
+	providedDB     struct{ dsn string }
+	providedCache  struct{ db *providedDB }
+)
+
+func TestProvide(t *testing.T) {
+	var (
+		c     = di.New()
+		calls = 0
+	)
+
+	di.Set(c, di.OptSetup(func() (providedConfig, error) {
+		return providedConfig{dsn: "dsn"}, nil
+	}))
+
+	err := di.Provide(c, func(cfg providedConfig) (*providedDB, *providedCache, error) {
+		calls++
+		db := &providedDB{dsn: cfg.dsn}
+		return db, &providedCache{db: db}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, cache := di.Get2[*providedDB, *providedCache](c)
+	if db.dsn != "dsn" || cache.db != db {
+		t.Errorf("Unexpected: %v %v", db, cache)
+	}
+
+	if calls != 1 {
+		t.Errorf("Unexpected: %v", calls)
+	}
+
+	if err := di.Reset[*providedCache](c); err != nil {
+		t.Fatal(err)
+	}
+
+	if cache := di.Get[*providedCache](c); cache.db == db || calls != 2 {
+		t.Errorf("Constructor should be called again: %v", calls)
+	}
+}
+
+func TestSetAfterProvide(t *testing.T) {
+	var (
+		c       = di.New()
+		cleaned = 0
+	)
+
+	err := di.Provide(c, func() (*providedDB, func(), error) {
+		return &providedDB{dsn: "dsn"}, func() { cleaned++ }, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if db := di.Get[*providedDB](c); db.dsn != "dsn" {
+		t.Errorf("Unexpected: %v", db)
+	}
+
+	di.Set(c, di.OptMiddleware(func(db *providedDB) (*providedDB, error) {
+		return &providedDB{dsn: db.dsn + "+mw"}, nil
+	}))
+
+	scope := c.BeginScope()
+	di.Set(scope.Container, di.OptMiddleware(func(db *providedDB) (*providedDB, error) {
+		return &providedDB{dsn: db.dsn + "+scope"}, nil
+	}))
+
+	if db := di.Get[*providedDB](scope.Container); db.dsn != "dsn+mw+scope" {
+		t.Errorf("Unexpected: %v", db)
+	}
+
+	if err := scope.End(); err != nil || cleaned != 1 {
+		t.Errorf("Unexpected: %v, %v", err, cleaned)
+	}
+
+	if err := di.Swap(c, &providedDB{dsn: "swapped"}, 0); err != nil || cleaned != 2 {
+		t.Errorf("Unexpected: %v, %v", err, cleaned)
+	}
+
+	if db := di.Get[*providedDB](c); db.dsn != "swapped" {
+		t.Errorf("Unexpected: %v", db)
+	}
+}
+
+type (
+	providedStringer struct{}
+	providedCtxKey   struct{}
+)
+
+func (providedStringer) String() string { return "bound" }
+
+func TestProvideResolution(t *testing.T) {
+	type params struct {
+		di.In
+		Ctx      context.Context
+		Stringer fmt.Stringer
+	}
+
+	var (
+		c     = di.New()
+		ctx   = context.WithValue(context.Background(), providedCtxKey{}, "scope")
+		scope = c.BeginScopeContext(ctx)
+	)
+
+	di.Set(c, di.OptSetup(func() (providedStringer, error) {
+		return providedStringer{}, nil
+	}), di.OptAutoBindInterfaces[providedStringer]())
+
+	err := di.Provide(scope.Container, func(ctx context.Context, s fmt.Stringer, p params) (*providedDB, error) {
+		return &providedDB{dsn: fmt.Sprintf("%v %v %v %v", ctx.Value(providedCtxKey{}), s, p.Ctx == ctx, p.Stringer)}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if db, err := di.TryGet[*providedDB](scope.Container); err != nil || db.dsn != "scope bound true bound" {
+		t.Errorf("Unexpected: %v, %v", db, err)
+	}
+}
+
+func TestProvideSet(t *testing.T) {
+	var (
+		c   = di.New()
+		set = []any{
+			func() providedConfig { return providedConfig{dsn: "dsn"} },
+			func(cfg providedConfig) (*providedDB, error) { return &providedDB{dsn: cfg.dsn}, nil },
+			func(db *providedDB) *providedCache { return &providedCache{db: db} },
+		}
+	)
+
+	if err := di.Provide(c, set...); err != nil {
+		t.Fatal(err)
+	}
+
+	if cache := di.Get[*providedCache](c); cache.db.dsn != "dsn" {
+		t.Errorf("Unexpected: %v", cache.db)
+	}
+
+	if err := di.Provide(c, "db", 42); err == nil {
+		t.Errorf("Error expected")
+	}
+}
+
+func TestProvideNamed(t *testing.T) {
+	c := di.New()
+
+	if err := di.ProvideNamed(c, "replica", func(*di.Container) (*providedDB, error) {
+		return &providedDB{dsn: "replica"}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if val := di.GetNamed[*providedDB](c, "replica"); val.dsn != "replica" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if entities := c.Entities(); len(entities) != 1 || entities[0].String() != "replica<*di_test.providedDB>" {
+		t.Errorf("Unexpected: %v", entities)
+	}
+}
+
+func TestProvideErrors(t *testing.T) {
+	var (
+		c       = di.New()
+		errTest = errors.New("test")
+	)
+
+	for _, constructor := range []any{
+		nil,
+		42,
+		func() {},
+		func() error { return nil },
+		func() (int, int) { return 0, 0 },
+	} {
+		if err := di.Provide(c, constructor); err == nil {
+			t.Errorf("Provide should fail on %T", constructor)
+		}
+	}
+
+	if err := di.Provide(c, func() (string, error) { return "", errTest }); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := di.TryGet[string](c); !errors.Is(err, errTest) {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if err := di.Provide(c, func(providedConfig) (int, error) { return 0, nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := di.TryGet[int](c); err == nil {
+		t.Errorf("TryGet should fail on missing parameter")
+	}
+}
+
+func TestProvideCleanup(t *testing.T) {
+	var (
+		c        = di.New()
+		released = 0
+	)
+
+	if err := di.Provide(c, func() (*providedDB, *providedCache, func(), error) {
+		db := &providedDB{}
+		return db, &providedCache{db: db}, func() { released++ }, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	di.Get2[*providedDB, *providedCache](c)
+
+	if err := di.Reset[*providedDB](c); err != nil {
+		t.Fatal(err)
+	}
+
+	if released != 0 {
+		t.Errorf("Cleanup should wait for cache: %v", released)
+	}
+
+	if err := c.Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+
+	if released != 1 {
+		t.Errorf("Unexpected: %v", released)
+	}
+}
+
+type (
+	providedHandler interface{ Route() string }
+	providedRouter  struct{ routes []string }
+	providedRoute   string
+)
+
+func (r providedRoute) Route() string { return string(r) }
+
+func TestProvideVariadic(t *testing.T) {
+	c := di.New()
+
+	if err := di.Provide(c, func(handlers ...providedHandler) *providedRouter {
+		router := &providedRouter{}
+		for _, h := range handlers {
+			router.routes = append(router.routes, h.Route())
+		}
+		return router
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, route := range []string{"users", "orders"} {
+		di.SetNamed(c, route, di.OptSetup(func() (providedHandler, error) {
+			return providedRoute("/" + route), nil
+		}))
+	}
+
+	if val := di.Get[*providedRouter](c); fmt.Sprint(val.routes) != "[/orders /users]" {
+		t.Errorf("Unexpected: %v", val.routes)
+	}
+
+	if err := di.Provide(c, func(handlers ...int) string { return fmt.Sprint(len(handlers)) }); err != nil {
+		t.Fatal(err)
+	}
+
+	if val := di.Get[string](c); val != "0" {
+		t.Errorf("Unexpected: %v", val)
+	}
+}
@@ -0,0 +1,49 @@
+package di
+
+import (
+	"fmt"
+	"sync"
+)
+
+// OptLazyProxy makes Get return proxy built by newProxy instead of the entity
+// itself, entity is built on the first call of get, e.g. by the first proxy
+// method call. That keeps singleton lazy even when it must be handed over
+// eagerly, like http.Handler to router. Go can't generate methods at runtime,
+// so proxy is provided by caller:
+//
+//	di.OptLazyProxy(func(get func() http.Handler) http.Handler {
+//		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//			get().ServeHTTP(w, r)
+//		})
+//	})
+func OptLazyProxy[T any](newProxy func(get func() T) T) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.proxy = newProxy }
+}
+
+// lazy returns getter which builds entity on the first call and panics
+// like Get on failure
+func (e *entityImpl[T]) lazy(c *Container) func() T {
+	var (
+		once sync.Once
+		val  T
+		err  error
+	)
+
+	return func() T {
+		once.Do(func() {
+			var release func()
+			if val, release, err = e.build(c); err != nil {
+				err = fmt.Errorf("setup dependency %v: %w", e.info(), err)
+				return
+			}
+
+			c.pushCleanup(e, val, e.destructor(val, release))
+		})
+
+		if err != nil {
+			c.fail(e.info(), err)
+		}
+
+		return val
+	}
+}
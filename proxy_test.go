@@ -0,0 +1,49 @@
+package di_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestLazyProxy(t *testing.T) {
+	var (
+		c       = di.New()
+		built   = 0
+		cleaned = 0
+	)
+
+	di.Set(c, di.OptSetup(func() (http.Handler, error) {
+		built++
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}), nil
+	}), di.OptCleanup(func(http.Handler) error {
+		cleaned++
+		return nil
+	}), di.OptLazyProxy(func(get func() http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			get().ServeHTTP(w, r)
+		})
+	}))
+
+	handler := di.Get[http.Handler](c)
+	if built != 0 {
+		t.Errorf("Handler shouldn't be built before the first call")
+	}
+
+	for range 2 {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if rec.Code != http.StatusTeapot || built != 1 {
+			t.Errorf("Unexpected: %v, %v", rec.Code, built)
+		}
+	}
+
+	if err := c.Cleanup(); err != nil || cleaned != 1 {
+		t.Errorf("Unexpected: %v, %v", err, cleaned)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build race
+
+package di_test
+
+// race detector instrumentation allocates, allocation tests skip under it
+const raceEnabled = true
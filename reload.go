@@ -0,0 +1,97 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// OptReloadable entity (config, log level, TLS certs) is rebuilt
+// by Container.Reload
+func OptReloadable[T any]() func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.reloadable = true }
+}
+
+// Reload rebuilds built reloadable entities and reapplies middlewares of
+// entities depending on them, transitively, so e.g. servers pick changes up
+// without restart. Such middlewares have to tolerate being applied again.
+// Dependents are the ones recorded by Graph. Old instance is cleaned up once
+// the new one is built, entity which fails to rebuild keeps the old one.
+// Reloadable entities whose setup failed are built again by the next Get.
+func (c *Container) Reload() error {
+	var (
+		root     = c.root()
+		errs     = []error{}
+		reloaded = map[entity]bool{}
+	)
+
+	root.beginTransition()
+	defer root.endTransition()
+
+	for _, e := range c.query(func(e Entity) bool { return e.Reloadable }) {
+		entity, owner := c.lookup(e.key)
+		if !e.Built {
+			// drops remembered setup error
+			entity.reset()
+			continue
+		}
+
+		val, cleanupFn, err := entity.rebuild(owner, owner.step(entity))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reload %v: %w", e, err))
+			continue
+		}
+
+		old := owner.takeCleanups(entity)
+		owner.pushCleanup(entity, val, cleanupFn)
+		for i := len(old) - 1; i >= 0; i-- {
+			_, err := owner.runCleanup(old[i])
+			errs = append(errs, err)
+		}
+
+		queue := root.dependentsOf(entity)
+		for len(queue) > 0 {
+			dependent := queue[0]
+			queue = queue[1:]
+
+			if reloaded[dependent] {
+				continue
+			}
+			reloaded[dependent] = true
+
+			if err := dependent.reapply(c); err != nil {
+				errs = append(errs, err)
+			}
+
+			queue = append(queue, root.dependentsOf(dependent)...)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ReloadOnSignal calls Reload on each of signals, SIGHUP by default, until
+// ctx is done. Reload errors are passed to onErr.
+func (c *Container) ReloadOnSignal(ctx context.Context, onErr func(error), signals ...os.Signal) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+			if err := c.Reload(); err != nil && onErr != nil {
+				onErr(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/irr123/di"
+)
+
+type (
+	logLevel string
+	server   struct{ level logLevel }
+)
+
+func TestReload(t *testing.T) {
+	var (
+		c     = di.New()
+		level = logLevel("info")
+		built = 0
+	)
+
+	di.Set(c, di.OptSetup(func() (logLevel, error) {
+		return level, nil
+	}), di.OptReloadable[logLevel]())
+	di.Set(c, di.OptSetup(func() (*server, error) {
+		built++
+		return &server{}, nil
+	}), di.OptMiddlewareC(func(c *di.Container, s *server) (*server, error) {
+		s.level = di.Get[logLevel](c)
+		return s, nil
+	}))
+
+	srv := di.Get[*server](c)
+	if srv.level != "info" {
+		t.Errorf("Unexpected: %v", srv.level)
+	}
+
+	level = "debug"
+	if err := c.Reload(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if srv.level != "debug" || di.Get[*server](c) != srv || built != 1 {
+		t.Errorf("Unexpected: %v, %v", srv.level, built)
+	}
+}
+
+func TestReloadOnSignal(t *testing.T) {
+	var (
+		c        = di.New()
+		reloaded = make(chan struct{}, 2)
+	)
+
+	di.Set(c, di.OptSetup(func() (logLevel, error) {
+		reloaded <- struct{}{}
+		return "info", nil
+	}), di.OptReloadable[logLevel]())
+	di.Get[logLevel](c)
+	<-reloaded
+
+	// keeps process alive if signal comes before ReloadOnSignal subscribes
+	ignore := make(chan os.Signal, 1)
+	signal.Notify(ignore, syscall.SIGHUP)
+	defer signal.Stop(ignore)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		c.ReloadOnSignal(ctx, func(err error) { t.Errorf("Unexpected: %v", err) }, syscall.SIGHUP)
+		close(done)
+	}()
+
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.After(5 * time.Second)
+	for sent := false; !sent; {
+		if err := self.Signal(syscall.SIGHUP); err != nil {
+			t.Skipf("Can't send signal: %v", err)
+		}
+
+		select {
+		case <-reloaded:
+			sent = true
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("Reload wasn't triggered")
+		}
+	}
+
+	cancel()
+	<-done
+}
+
+func TestReloadFailure(t *testing.T) {
+	type config string
+
+	var (
+		c       = di.New()
+		bad     = false
+		cleaned = []config{}
+	)
+
+	di.Set(c, di.OptSetup(func() (config, error) {
+		if bad {
+			return "", errors.New("bad config")
+		}
+
+		return "good", nil
+	}), di.OptCleanup(func(cfg config) error {
+		cleaned = append(cleaned, cfg)
+		return nil
+	}), di.OptReloadable[config]())
+
+	di.Get[config](c)
+
+	bad = true
+	if err := c.Reload(); err == nil {
+		t.Errorf("Error expected")
+	}
+
+	if val, err := di.TryGet[config](c); err != nil || val != "good" || len(cleaned) != 0 {
+		t.Errorf("Old instance should be kept: %v, %v, %v", val, err, cleaned)
+	}
+
+	bad = false
+	if err := c.Reload(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if val, err := di.TryGet[config](c); err != nil || val != "good" || len(cleaned) != 1 {
+		t.Errorf("Unexpected: %v, %v, %v", val, err, cleaned)
+	}
+
+	c = di.New()
+	bad = true
+	di.Set(c, di.OptSetup(func() (config, error) {
+		if bad {
+			return "", errors.New("bad config")
+		}
+
+		return "fixed", nil
+	}), di.OptReloadable[config]())
+
+	if _, err := di.TryGet[config](c); err == nil {
+		t.Errorf("Error expected")
+	}
+
+	bad = false
+	if err := c.Reload(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if val, err := di.TryGet[config](c); err != nil || val != "fixed" {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+}
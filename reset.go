@@ -0,0 +1,90 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Reset cleans up entity if it's built and drops cached instance, so the next
+// Get builds it again, e.g. to recover client whose credentials were revoked
+func Reset[T any](c *Container) error {
+	return ResetNamed[T](c, "")
+}
+
+// ResetNamed cleans up named entity and drops cached instance
+func ResetNamed[T any](c *Container, name string) error {
+	entity, owner := c.lookup(c.redirect(entityKey[T](c.named(name))))
+	if entity == nil {
+		return nil
+	}
+
+	return owner.resetEntity(entity)
+}
+
+// resetEntity runs pending cleanups of entity owned by c and resets it
+func (c *Container) resetEntity(entity entity) error {
+	errs := []error{}
+	pending := c.takeCleanups(entity)
+	for i := len(pending) - 1; i >= 0; i-- {
+		_, err := c.runCleanup(pending[i])
+		errs = append(errs, err)
+	}
+
+	entity.reset()
+
+	return errors.Join(errs...)
+}
+
+// Release cleans up instance got from container before the container (or
+// scope) it's resolved in is cleaned up, e.g. transient one which would
+// otherwise keep its cleanup pending till shutdown. Instance type has to be
+// comparable, e.g. a pointer.
+func Release[T any](c *Container, val T) error {
+	if !reflect.ValueOf(&val).Elem().Comparable() {
+		return fmt.Errorf("release: %v isn't comparable", reflect.TypeFor[T]())
+	}
+
+	for owner := c; owner != nil; owner = owner.parent {
+		if cleanup, ok := owner.takeCleanupOf(val); ok {
+			_, err := owner.runCleanup(cleanup)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// takeCleanupOf the latest instance equal to val out of cleanup stack of c
+func (c *Container) takeCleanupOf(val any) (cleanup, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := len(c.cleanup) - 1; i >= 0; i-- {
+		if taken := c.cleanup[i]; taken.val == val {
+			c.cleanup = slices.Delete(c.cleanup, i, i+1)
+			return taken, true
+		}
+	}
+
+	return cleanup{}, false
+}
+
+// takeCleanups of entity out of cleanup stack of c
+func (c *Container) takeCleanups(entity entity) []cleanup {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	taken := []cleanup{}
+	c.cleanup = slices.DeleteFunc(c.cleanup, func(cleanup cleanup) bool {
+		if cleanup.source == entity {
+			taken = append(taken, cleanup)
+			return true
+		}
+
+		return false
+	})
+
+	return taken
+}
@@ -0,0 +1,76 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestReset(t *testing.T) {
+	var (
+		c       = di.New()
+		count   = 0
+		cleaned = 0
+	)
+
+	di.Set(c, di.OptSetup(func() (int, error) {
+		count++
+		return count, nil
+	}), di.OptCleanup(func(int) error {
+		cleaned++
+		return nil
+	}))
+
+	if val := di.Get[int](c); val != 1 {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if err := di.Reset[int](c); err != nil || cleaned != 1 {
+		t.Errorf("Unexpected: %v, %v", err, cleaned)
+	}
+
+	if val := di.Get[int](c); val != 2 {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if err := c.Cleanup(); err != nil || cleaned != 2 {
+		t.Errorf("Unexpected: %v, %v", err, cleaned)
+	}
+
+	if err := di.Reset[string](c); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	type conn struct{ closed bool }
+
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (*conn, error) {
+		return &conn{}, nil
+	}), di.OptCleanup(func(c *conn) error {
+		c.closed = true
+		return nil
+	}), di.OptNoReuse[*conn]())
+	di.Set(c, di.OptSetup(func() ([]int, error) {
+		return nil, nil
+	}), di.OptCleanup(func([]int) error { return nil }))
+
+	s := c.BeginScope()
+	conns := []*conn{di.Get[*conn](c), di.Get[*conn](s.Container), di.Get[*conn](s.Container)}
+
+	for _, conn := range conns[:2] {
+		if err := di.Release(s.Container, conn); err != nil || !conn.closed {
+			t.Errorf("Unexpected: %v, %v", err, conn.closed)
+		}
+	}
+
+	if len(c.Pending()) != 0 || len(s.Pending()) != 1 || conns[2].closed {
+		t.Errorf("Unexpected: %v, %v", c.Pending(), s.Pending())
+	}
+
+	if err := di.Release(c, di.Get[[]int](c)); err == nil {
+		t.Errorf("Error expected")
+	}
+}
@@ -0,0 +1,58 @@
+package di
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// OptRestrictTo allows resolving entity only from listed packages, e.g.
+// payments client from "example.com/shop/payments/...", where "/..." matches
+// package and its subpackages. Get from any other package, including setups
+// of entities living there, fails. Caller is the first function outside of
+// this package, so the check can't tell helpers from their users. Caller of
+// GetAsync or Prefetch is checked for Gets they run in background, Get
+// without any caller outside of this package fails.
+func OptRestrictTo[T any](packages ...string) func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.restrictedTo = append(s.restrictedTo, packages...) }
+}
+
+func checkRestriction(entity entity, allowed []string, caller *runtime.Frame) error {
+	frame, ok := callerFrame()
+	if !ok && caller != nil {
+		frame, ok = *caller, true
+	}
+
+	if !ok {
+		return fmt.Errorf("%v is restricted to %v, resolved without caller", entity.info(), allowed)
+	}
+
+	pkg := funcPackage(frame.Function)
+	for _, pattern := range allowed {
+		if prefix, tree := strings.CutSuffix(pattern, "/..."); tree {
+			if pkg == prefix || strings.HasPrefix(pkg, prefix+"/") {
+				return nil
+			}
+		} else if pkg == pattern {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%v is restricted to %v, resolved from %s (%s:%d)",
+		entity.info(), allowed, pkg, frame.File, frame.Line)
+}
+
+// funcPackage returns import path of package of function named as
+// runtime.Frame.Function, e.g. "example.com/pkg.(*T).Method"
+func funcPackage(function string) string {
+	if i := strings.IndexByte(function, '['); i >= 0 {
+		function = function[:i]
+	}
+
+	slash := strings.LastIndexByte(function, '/')
+	if dot := strings.IndexByte(function[slash+1:], '.'); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+
+	return function
+}
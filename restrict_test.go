@@ -0,0 +1,57 @@
+package di_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestOptRestrictTo(t *testing.T) {
+	c := di.New()
+
+	di.SetNamed(c, "admin", di.OptSetup(func() (string, error) {
+		return "admin", nil
+	}), di.OptRestrictTo[string]("github.com/irr123/di_test"))
+	di.SetNamed(c, "payments", di.OptSetup(func() (string, error) {
+		return "payments", nil
+	}), di.OptRestrictTo[string]("example.com/shop/payments/...", "github.com/irr123"))
+	di.Set(c, di.OptSetupC(func(c *di.Container) (int, error) {
+		return len(di.GetNamed[string](c, "admin")), nil
+	}))
+
+	if val, err := di.TryGetNamed[string](c, "admin"); err != nil || val != "admin" {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+
+	if val, err := tryGetGeneric[string](c, "admin"); err != nil || val != "admin" {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+
+	if val, err := di.TryGet[int](c); err != nil || val != 5 {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+
+	if _, err := di.GetNamedAsync[string](c, "payments").Wait(context.Background()); err == nil {
+		t.Errorf("GetNamedAsync should fail from restricted package")
+	}
+
+	if err := di.WithNamed(c, "payments", func(string) error { return nil }); err == nil {
+		t.Errorf("WithNamed should fail from restricted package")
+	}
+
+	if val, err := di.GetNamedAsync[string](c, "admin").Wait(context.Background()); err != nil || val != "admin" {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+
+	_, err := di.TryGetNamed[string](c, "payments")
+	if err == nil || !strings.Contains(err.Error(), "payments<string> is restricted to "+
+		"[example.com/shop/payments/... github.com/irr123], resolved from github.com/irr123/di_test") {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
+
+func tryGetGeneric[T any](c *di.Container, name string) (T, error) {
+	return di.TryGetNamed[T](c, name)
+}
@@ -0,0 +1,87 @@
+package di
+
+import (
+	"context"
+	"reflect"
+)
+
+// Scope is a child container with its own cache and cleanup stack.
+// Entities registered in parent are visible through it, the ones marked
+// by OptScoped are built once per scope. Scoped and transient instances
+// resolved in scope are cleaned up on its End.
+type Scope struct {
+	*Container
+}
+
+// BeginScope starts scope sharing context with its parent, e.g. per work item
+// of batch job
+func (c *Container) BeginScope() *Scope {
+	return c.BeginScopeContext(c.ctx)
+}
+
+// BeginScopeContext starts scope bound to ctx, e.g. per incoming request
+func (c *Container) BeginScopeContext(ctx context.Context) *Scope {
+	s := c.newScope(ctx)
+
+	c.mu.Lock()
+	c.scopes = append(c.scopes, s.Container)
+	c.mu.Unlock()
+
+	return s
+}
+
+// newScope which isn't tracked by c
+func (c *Container) newScope(ctx context.Context) *Scope {
+	s := New()
+	s.parent, s.ctx, s.namespace = c, ctx, c.namespace
+
+	return &Scope{Container: s}
+}
+
+// Detach starts scope for background work which outlives s. It keeps values
+// of s context but not its cancellation, resolves entities through the root
+// container and is ended only by its own End, neither by s nor by root Cleanup.
+func (s *Scope) Detach() *Scope {
+	return s.root().newScope(context.WithoutCancel(s.ctx))
+}
+
+// End will deinitialize entities created in scope and its nested scopes
+func (s *Scope) End() error {
+	return s.Cleanup()
+}
+
+// scopeContext returns context of scope c as context.Context entity, unless
+// it's registered in c itself. It isn't registered by scope, so the scope
+// allocates nothing until it needs to.
+func (c *Container) scopeContext(typ reflect.Type, name string) (any, bool) {
+	if c.parent == nil || name != "" || typ != contextType {
+		return nil, false
+	}
+
+	if c.registeredIn(keyFor(typ, c.named(name))) {
+		return nil, false
+	}
+
+	return c.ctx, true
+}
+
+var contextType = reflect.TypeFor[context.Context]()
+
+// registeredIn tells if entity is registered in c itself
+func (c *Container) registeredIn(entityName string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if len(c.entities) == 0 {
+		return false
+	}
+
+	_, ok := c.entities[entityName]
+
+	return ok
+}
+
+// OptScoped entity is built once per scope and cleaned up on its End
+func OptScoped[T any]() func(*entityImpl[T]) {
+	return func(s *entityImpl[T]) { s.scope = true }
+}
@@ -0,0 +1,187 @@
+package di_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestScope(t *testing.T) {
+	var (
+		c       = di.New()
+		count   = 0
+		cleaned = []string{}
+	)
+
+	di.Set(c, di.OptSetup(func() (string, error) {
+		return "singleton", nil
+	}), di.OptCleanup(func(s string) error {
+		cleaned = append(cleaned, s)
+		return nil
+	}))
+	di.Set(c, di.OptSetupC(func(c *di.Container) (int, error) {
+		di.Get[string](c)
+		count++
+		return count, nil
+	}), di.OptCleanup(func(i int) error {
+		cleaned = append(cleaned, fmt.Sprint(i))
+		return nil
+	}), di.OptScoped[int]())
+
+	s1 := c.BeginScope()
+	for range 2 {
+		if val := di.Get[int](s1.Container); val != 1 {
+			t.Errorf("Unexpected: %v", val)
+		}
+	}
+
+	s2 := c.BeginScope()
+	if val := di.Get[int](s2.Container); val != 2 {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if err := s1.End(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	nested := s2.BeginScope()
+	if val := di.Get[int](nested.Container); val != 3 {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if err := c.Cleanup(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if fmt.Sprint(cleaned) != "[1 3 2 singleton]" {
+		t.Errorf("Unexpected: %v", cleaned)
+	}
+}
+
+func TestDetachedScope(t *testing.T) {
+	var (
+		c       = di.New()
+		cleaned = 0
+	)
+
+	di.Set(c, di.OptSetup(func() (int, error) {
+		return 42, nil
+	}), di.OptCleanup(func(int) error {
+		cleaned++
+		return nil
+	}), di.OptScoped[int]())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	request := c.BeginScopeContext(ctx)
+	background := request.Detach()
+
+	di.Get[int](request.Container)
+	di.Get[int](background.Container)
+
+	cancel()
+	if err := request.End(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if err := c.Cleanup(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if cleaned != 1 {
+		t.Errorf("Detached scope shouldn't be cleaned up: %v", cleaned)
+	}
+
+	if err := di.Get[context.Context](background.Container).Err(); err != nil {
+		t.Errorf("Detached scope context shouldn't be canceled: %v", err)
+	}
+
+	if err := background.End(); err != nil || cleaned != 2 {
+		t.Errorf("Unexpected: %v, %v", err, cleaned)
+	}
+}
+
+func TestScopeOverride(t *testing.T) {
+	type (
+		tx   string
+		repo string
+	)
+
+	var (
+		c       = di.New()
+		cleaned = []tx{}
+	)
+
+	di.Set(c, di.OptSetup(func() (repo, error) {
+		return "repo", nil
+	}))
+
+	s1, s2 := c.BeginScope(), c.BeginScope()
+	di.Set(s1.Container, di.OptSetup(func() (tx, error) {
+		return "tx", nil
+	}), di.OptCleanup(func(tx tx) error {
+		cleaned = append(cleaned, tx)
+		return nil
+	}))
+	di.Set(s1.Container, di.OptMiddleware(func(r repo) (repo, error) {
+		return r + "+" + repo(di.Get[tx](s1.Container)), nil
+	}))
+
+	if val := di.Get[repo](s1.Container); val != "repo+tx" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if val := di.Get[repo](s2.Container); val != "repo" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if val := di.Get[repo](c); val != "repo" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if err := s1.End(); err != nil || len(cleaned) != 1 {
+		t.Errorf("Unexpected: %v, %v", err, cleaned)
+	}
+}
+
+func TestScopeTransientCleanup(t *testing.T) {
+	var (
+		c       = di.New()
+		cleaned = 0
+	)
+
+	di.Set(c, di.OptSetup(func() (int, error) {
+		return 42, nil
+	}), di.OptCleanup(func(int) error {
+		cleaned++
+		return nil
+	}), di.OptNoReuse[int]())
+
+	s := c.BeginScope()
+	di.Get[int](s.Container)
+	di.Get[int](s.Container)
+
+	if err := s.End(); err != nil || cleaned != 2 {
+		t.Errorf("Unexpected: %v, %v", err, cleaned)
+	}
+}
+
+func TestScopeAllocations(t *testing.T) {
+	c := di.New()
+
+	if allocs := testing.AllocsPerRun(100, func() { di.New() }); allocs > 2 {
+		t.Errorf("Unexpected: %v", allocs)
+	}
+
+	// scope, its container and state, the rest is allocated once needed
+	allocs := testing.AllocsPerRun(100, func() {
+		s := c.BeginScope()
+		_ = di.Get[context.Context](s.Container)
+		_ = s.End()
+	})
+
+	if allocs > 3 {
+		t.Errorf("Unexpected: %v", allocs)
+	}
+}
@@ -0,0 +1,50 @@
+package di
+
+import "reflect"
+
+// Self describes entity being built, see OptSetupSelf
+type Self struct {
+	Entity Entity
+	// Requester is entity whose setup got this one through container it
+	// was given, it's zero for Get made outside of setups
+	Requester Entity
+	// Scope is container entity is built in
+	Scope *Container
+}
+
+var selfType = reflect.TypeFor[Self]()
+
+// OptSetupSelf entity "constructor" which receives description of entity
+// itself, e.g. transient logger could name itself after its requester:
+//
+//	di.OptSetupSelf(func(self di.Self) (*slog.Logger, error) {
+//		return slog.With("component", self.Requester.Name), nil
+//	})
+//
+// Parameter of type Self of Provide constructor gets the same.
+func OptSetupSelf[T any](f func(Self) (T, error)) func(*entityImpl[T]) {
+	return OptSetupC(func(c *Container) (T, error) { return f(c.self()) })
+}
+
+// self describes entity whose setup got c
+func (c *Container) self() Self {
+	if c.chain == nil {
+		return Self{Scope: c}
+	}
+
+	self := Self{Scope: c.chain.scope}
+	self.Entity = c.describe(c.chain.entity)
+	if c.chain.parent != nil {
+		self.Requester = c.describe(c.chain.parent.entity)
+	}
+
+	return self
+}
+
+// describe entity, so its Get works as Get made outside of setups
+func (c *Container) describe(entity entity) Entity {
+	info := entity.info()
+	info.c, info.key = &Container{state: c.state}, keyFor(info.Type, info.Name)
+
+	return info
+}
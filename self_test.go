@@ -0,0 +1,141 @@
+package di_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+type (
+	namedLogger string
+	component   struct{ log namedLogger }
+)
+
+func TestOptSetupSelf(t *testing.T) {
+	c := di.New()
+
+	di.Set(c, di.OptSetupSelf(func(self di.Self) (namedLogger, error) {
+		return namedLogger(self.Requester.Name + ":" + self.Entity.Type.String()), nil
+	}), di.OptNoReuse[namedLogger]())
+	di.SetNamed(c, "billing", di.OptSetupC(func(c *di.Container) (component, error) {
+		return component{log: di.Get[namedLogger](c)}, nil
+	}))
+	di.SetNamed(c, "orders", di.OptSetupC(func(c *di.Container) (component, error) {
+		return component{log: di.Get[namedLogger](c)}, nil
+	}))
+
+	if val := di.GetNamed[component](c, "billing"); val.log != "billing:di_test.namedLogger" {
+		t.Errorf("Unexpected: %v", val.log)
+	}
+
+	if val := di.GetNamed[component](c, "orders"); val.log != "orders:di_test.namedLogger" {
+		t.Errorf("Unexpected: %v", val.log)
+	}
+
+	if val := di.Get[namedLogger](c); val != ":di_test.namedLogger" {
+		t.Errorf("Unexpected: %v", val)
+	}
+}
+
+func TestProvideSelf(t *testing.T) {
+	var (
+		c    = di.New()
+		self di.Self
+	)
+
+	scope := c.BeginScope()
+	if err := di.ProvideNamed(scope.Container, "self", func(s di.Self) int {
+		self = s
+		return 42
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	di.GetNamed[int](scope.Container, "self")
+
+	if self.Entity.Name != "self" || self.Scope != scope.Container {
+		t.Errorf("Unexpected: %+v", self)
+	}
+
+	if val, err := self.Entity.Get(); err != nil || val != 42 {
+		t.Errorf("Unexpected: %v %v", val, err)
+	}
+}
+
+func TestConcurrentResolutions(t *testing.T) {
+	type (
+		logger string
+		a      struct{ log logger }
+		b      struct{ log logger }
+	)
+
+	var (
+		c       = di.New()
+		started sync.WaitGroup
+	)
+
+	started.Add(2)
+	di.Set(c, di.OptSetupSelf(func(self di.Self) (logger, error) {
+		return logger(self.Requester.String()), nil
+	}), di.OptNoReuse[logger]())
+	di.Set(c, di.OptSetupC(func(c *di.Container) (*a, error) {
+		started.Done()
+		started.Wait()
+		return &a{log: di.Get[logger](c)}, nil
+	}))
+	di.Set(c, di.OptSetupC(func(c *di.Container) (*b, error) {
+		started.Done()
+		started.Wait()
+		return &b{log: di.Get[logger](c)}, nil
+	}))
+
+	fa, fb := di.GetAsync[*a](c), di.GetAsync[*b](c)
+	valA, errA := fa.Wait(context.Background())
+	valB, errB := fb.Wait(context.Background())
+	if errA != nil || errB != nil || valA.log != "<*di_test.a>" || valB.log != "<*di_test.b>" {
+		t.Errorf("Unexpected: %v, %v, %v, %v", valA, valB, errA, errB)
+	}
+
+	edges := []string{}
+	for _, edge := range c.Graph() {
+		edges = append(edges, fmt.Sprintf("%v -> %v", edge.From, edge.To))
+	}
+
+	if expected := []string{"<*di_test.a> -> <di_test.logger>", "<*di_test.b> -> <di_test.logger>"}; !slices.Equal(edges, expected) {
+		t.Errorf("Unexpected: %v", edges)
+	}
+}
+
+func TestDependencyCycle(t *testing.T) {
+	type (
+		a struct{}
+		b struct{}
+	)
+
+	c := di.New()
+
+	di.Set(c, di.OptSetupC(func(c *di.Container) (*a, error) {
+		_, err := di.TryGet[*b](c)
+		return &a{}, err
+	}))
+	di.Set(c, di.OptSetupC(func(c *di.Container) (*b, error) {
+		_, err := di.TryGet[*a](c)
+		return &b{}, err
+	}))
+	di.Set(c, di.OptSetupC(func(c *di.Container) (int, error) {
+		return di.TryGet[int](c)
+	}))
+
+	if _, err := di.TryGet[*a](c); err == nil || !strings.Contains(err.Error(), "dependency cycle: <*di_test.a> -> <*di_test.b> -> <*di_test.a>") {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if _, err := di.TryGet[int](c); err == nil || !strings.Contains(err.Error(), "dependency cycle: <int> -> <int>") {
+		t.Errorf("Unexpected: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package di
+
+import "fmt"
+
+// Stats of container, e.g. to log on a timer or assert in smoke tests
+type Stats struct {
+	// Registered entities visible from container, Built and Transient are
+	// subsets of them
+	Registered int
+	Built      int
+	Transient  int
+	// Scopes not ended yet, nested ones included
+	Scopes int
+	// PendingCleanups of container and its scopes
+	PendingCleanups int
+	// Errors recorded by container
+	Errors int
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("registered=%d built=%d transient=%d scopes=%d pending_cleanups=%d errors=%d",
+		s.Registered, s.Built, s.Transient, s.Scopes, s.PendingCleanups, s.Errors)
+}
+
+// Stats returns counts of entities, scopes, pending cleanups and errors
+func (c *Container) Stats() Stats {
+	stats := Stats{Errors: len(c.Errors())}
+	for _, e := range c.Entities() {
+		stats.Registered++
+		if e.Built {
+			stats.Built++
+		}
+
+		if e.Transient {
+			stats.Transient++
+		}
+	}
+
+	c.countPending(&stats)
+
+	return stats
+}
+
+// countPending cleanups of c and its scopes
+func (c *Container) countPending(stats *Stats) {
+	c.mu.RLock()
+	stats.PendingCleanups += len(c.cleanup)
+	scopes := c.scopes
+	c.mu.RUnlock()
+
+	stats.Scopes += len(scopes)
+	for _, s := range scopes {
+		s.countPending(stats)
+	}
+}
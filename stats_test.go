@@ -0,0 +1,43 @@
+package di_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestStats(t *testing.T) {
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (int, error) {
+		return 42, nil
+	}), di.OptCleanup(func(int) error { return nil }))
+	di.Set(c, di.OptSetup(func() (string, error) {
+		return "", nil
+	}), di.OptCleanup(func(string) error { return nil }), di.OptNoReuse[string]())
+	di.Set(c, di.OptSetup(func() (float64, error) { return 0, errors.New("test") }))
+
+	di.Get[int](c)
+	s := c.BeginScope()
+	di.Get[string](s.Container)
+	di.Get[string](s.BeginScope().Container)
+	_, _ = di.TryGet[float64](c)
+	func() {
+		defer func() { _ = recover() }()
+		di.Get[float64](c)
+	}()
+
+	expected := di.Stats{Registered: 3, Built: 2, Transient: 1, Scopes: 2, PendingCleanups: 3, Errors: 1}
+	if stats := c.Stats(); stats != expected {
+		t.Errorf("Unexpected: %v", stats)
+	}
+
+	if err := s.End(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if stats := c.Stats(); stats.Scopes != 0 || stats.PendingCleanups != 1 {
+		t.Errorf("Unexpected: %v", stats)
+	}
+}
@@ -0,0 +1,64 @@
+// Package std registers commonly needed primitives, so applications don't
+// wire the same basics again and tests could override them, e.g. by fixed
+// Clock. Registrations which exist already are kept.
+package std
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/irr123/di"
+)
+
+type (
+	// Clock tells current time, override it to control time in tests
+	Clock interface {
+		Now() time.Time
+		Since(time.Time) time.Duration
+	}
+	// Propagator carries values of ctx over to background work, e.g.
+	// request ids, which shouldn't be canceled along with ctx
+	Propagator func(ctx context.Context) context.Context
+
+	systemClock struct{}
+	// lockedSource makes shared *rand.Rand safe for concurrent use
+	lockedSource struct {
+		mu  sync.Mutex
+		src rand.Source
+	}
+)
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) Since(t time.Time) time.Duration { return time.Since(t) }
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.src.Uint64()
+}
+
+// Register Clock, *rand.Rand, *slog.Logger, context.Context and Propagator
+// unless they're registered already. Registered *rand.Rand is shared, so it's
+// safe for concurrent use.
+func Register(c *di.Container) {
+	di.SetIfAbsent(c, di.OptSetup(func() (Clock, error) {
+		return systemClock{}, nil
+	}))
+	di.SetIfAbsent(c, di.OptSetup(func() (*rand.Rand, error) {
+		return rand.New(&lockedSource{src: rand.NewPCG(rand.Uint64(), rand.Uint64())}), nil
+	}))
+	di.SetIfAbsent(c, di.OptSetup(func() (*slog.Logger, error) {
+		return slog.Default(), nil
+	}))
+	di.SetIfAbsent(c, di.OptSetup(func() (context.Context, error) {
+		return context.Background(), nil
+	}))
+	di.SetIfAbsent(c, di.OptSetup(func() (Propagator, error) {
+		return context.WithoutCancel, nil
+	}))
+}
@@ -0,0 +1,70 @@
+package std_test
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/irr123/di"
+	"github.com/irr123/di/std"
+)
+
+type fixedClock time.Time
+
+func (f fixedClock) Now() time.Time { return time.Time(f) }
+
+func (f fixedClock) Since(t time.Time) time.Duration { return time.Time(f).Sub(t) }
+
+func TestRegister(t *testing.T) {
+	var (
+		c   = di.New()
+		now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	di.Set(c, di.OptSetup(func() (std.Clock, error) { return fixedClock(now), nil }))
+	std.Register(c)
+
+	if got := di.Get[std.Clock](c).Now(); !got.Equal(now) {
+		t.Errorf("Unexpected: %v", got)
+	}
+
+	if di.Get[*rand.Rand](c) == nil || di.Get[*slog.Logger](c) != slog.Default() {
+		t.Errorf("Unexpected defaults")
+	}
+
+	ctx, cancel := context.WithCancel(di.Get[context.Context](c))
+	cancel()
+
+	if err := di.Get[std.Propagator](c)(ctx).Err(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	s := c.BeginScopeContext(ctx)
+	if di.Get[context.Context](s.Container) != ctx {
+		t.Errorf("Scope context expected")
+	}
+}
+
+func TestRandConcurrent(t *testing.T) {
+	c := di.New()
+	std.Register(c)
+
+	var (
+		r  = di.Get[*rand.Rand](c)
+		wg sync.WaitGroup
+	)
+
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				r.IntN(10)
+			}
+		}()
+	}
+	wg.Wait()
+}
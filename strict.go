@@ -0,0 +1,43 @@
+package di
+
+import "fmt"
+
+// Strict makes container fail on the first recorded error instead of limping
+// along half-wired: subsequent Gets through it and its scopes return the
+// error, resolution of deprecated entities fails. Restart recovers it.
+func (c *Container) Strict() {
+	c.strict = true
+}
+
+// record error of container, the failed strict container keeps the first one
+func (c *Container) record(kind ErrorKind, entity Entity, err error) {
+	if err == nil {
+		return
+	}
+
+	c.errs.add(kind, entity, err)
+
+	root := c.root()
+	if !root.strict {
+		return
+	}
+
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	if root.failed == nil {
+		root.failed = fmt.Errorf("container failed: %w", err)
+	}
+}
+
+// failure of strict container
+func (c *Container) failure() error {
+	if !c.strict {
+		return nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.failed
+}
@@ -0,0 +1,49 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestStrict(t *testing.T) {
+	var (
+		c       = di.New()
+		errTest = errors.New("test")
+	)
+
+	c.Strict()
+	di.Set(c, di.OptSetup(func() (int, error) { return 0, errTest }))
+	di.Set(c, di.OptSetup(func() (string, error) { return "ok", nil }))
+	di.SetNamed(c, "old", di.OptSetup(func() (string, error) {
+		return "old", nil
+	}), di.OptDeprecated[string]("use new"))
+
+	if _, err := di.TryGetNamed[string](c, "old"); err == nil {
+		t.Errorf("Error expected")
+	}
+
+	if val, err := di.TryGet[string](c); err != nil || val != "ok" {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+
+	func() {
+		defer func() { _ = recover() }()
+		di.Get[int](c)
+	}()
+
+	if _, err := di.TryGet[string](c.BeginScope().Container); !errors.Is(err, errTest) {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	// Cleanup reports recorded errors
+	if err := c.Restart(context.Background()); !errors.Is(err, errTest) {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if val, err := di.TryGet[string](c); err != nil || val != "ok" {
+		t.Errorf("Unexpected: %v, %v", val, err)
+	}
+}
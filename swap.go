@@ -0,0 +1,52 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Swap replaces cached instance of entity by val. Users which already got
+// the old instance keep using it, its cleanup runs after grace period and
+// its errors are recorded to container. With zero grace cleanup runs
+// immediately and its error is returned.
+func Swap[T any](c *Container, val T, grace time.Duration) error {
+	return SwapNamed(c, "", val, grace)
+}
+
+// SwapNamed replaces cached instance of named entity by val
+func SwapNamed[T any](c *Container, name string, val T, grace time.Duration) error {
+	entityName := c.redirect(entityKey[T](c.named(name)))
+	found, owner := c.lookup(entityName)
+	if found == nil {
+		return fmt.Errorf("dependency not found: %s", entityName)
+	}
+
+	entity := retype[T](owner, entityName, found)
+	entity.inst.mu.Lock()
+	old := owner.takeCleanups(entity)
+	entity.inst.val = val
+	entity.inst.built.Store(true)
+	owner.pushCleanup(entity, val, entity.destructor(val, nil))
+	entity.inst.mu.Unlock()
+
+	cleanupOld := func() error {
+		errs := []error{}
+		for i := len(old) - 1; i >= 0; i-- {
+			_, err := owner.runCleanup(old[i])
+			errs = append(errs, err)
+		}
+
+		return errors.Join(errs...)
+	}
+
+	if grace <= 0 {
+		return cleanupOld()
+	}
+
+	time.AfterFunc(grace, func() {
+		owner.record(CleanupError, entity.info(), cleanupOld())
+	})
+
+	return nil
+}
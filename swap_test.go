@@ -0,0 +1,57 @@
+package di_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/irr123/di"
+)
+
+func TestSwap(t *testing.T) {
+	var (
+		c       = di.New()
+		cleaned = make(chan string, 3)
+	)
+
+	di.Set(c, di.OptSetup(func() (string, error) {
+		return "v1", nil
+	}), di.OptCleanup(func(s string) error {
+		cleaned <- s
+		return nil
+	}))
+
+	if val := di.Get[string](c); val != "v1" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if err := di.Swap(c, "v2", 10*time.Millisecond); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if val := di.Get[string](c); val != "v2" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	select {
+	case val := <-cleaned:
+		t.Errorf("Cleaned up before grace period: %v", val)
+	default:
+	}
+
+	if val := <-cleaned; val != "v1" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	if err := di.Swap(c, "v3", 0); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if err := c.Cleanup(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if val := fmt.Sprint(<-cleaned, <-cleaned); val != "v2v3" {
+		t.Errorf("Unexpected: %v", val)
+	}
+}
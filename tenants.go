@@ -0,0 +1,75 @@
+package di
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Tenants manages one scope per tenant key. Scopes are created lazily and
+// the least recently used ones are ended once there are more than size of
+// them, so tenant resources (schema connections, rate limiters) are isolated
+// and bounded. Tenants is safe for concurrent use.
+type Tenants[K comparable] struct {
+	c     *Container
+	size  int
+	setup func(K, *Scope)
+
+	mu     sync.Mutex
+	scopes map[K]*list.Element
+	lru    *list.List
+}
+
+type tenant[K comparable] struct {
+	key   K
+	scope *Scope
+}
+
+// NewTenants creates tenants factory, setup is called for each new scope to
+// register tenant specific entities. Size <= 0 keeps scopes of every tenant.
+func NewTenants[K comparable](c *Container, size int, setup func(K, *Scope)) *Tenants[K] {
+	return &Tenants[K]{
+		c:      c,
+		size:   size,
+		setup:  setup,
+		scopes: make(map[K]*list.Element),
+		lru:    list.New(),
+	}
+}
+
+// Get scope of tenant, evicted scope errors are recorded to container.
+// Evicted scopes are ended by Get which evicted them, without holding up
+// Gets of other tenants.
+func (t *Tenants[K]) Get(key K) *Scope {
+	s, evicted := t.get(key)
+	for _, scope := range evicted {
+		t.c.record(CleanupError, Entity{}, scope.End())
+	}
+
+	return s
+}
+
+func (t *Tenants[K]) get(key K) (*Scope, []*Scope) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if el, ok := t.scopes[key]; ok {
+		t.lru.MoveToFront(el)
+		return el.Value.(tenant[K]).scope, nil
+	}
+
+	s := t.c.BeginScope()
+	if t.setup != nil {
+		t.setup(key, s)
+	}
+
+	t.scopes[key] = t.lru.PushFront(tenant[K]{key: key, scope: s})
+
+	evicted := []*Scope{}
+	for t.size > 0 && t.lru.Len() > t.size {
+		tenant := t.lru.Remove(t.lru.Back()).(tenant[K])
+		delete(t.scopes, tenant.key)
+		evicted = append(evicted, tenant.scope)
+	}
+
+	return s, evicted
+}
@@ -0,0 +1,97 @@
+package di_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/irr123/di"
+)
+
+func TestTenants(t *testing.T) {
+	type schema string
+
+	var (
+		c       = di.New()
+		cleaned = []schema{}
+	)
+
+	tenants := di.NewTenants(c, 2, func(tenant string, s *di.Scope) {
+		di.Set(s.Container, di.OptSetup(func() (schema, error) {
+			return schema("schema_" + tenant), nil
+		}), di.OptCleanup(func(s schema) error {
+			cleaned = append(cleaned, s)
+			return nil
+		}))
+	})
+
+	for _, tenant := range []string{"a", "b", "a", "c", "a"} {
+		if val := di.Get[schema](tenants.Get(tenant).Container); val != schema("schema_"+tenant) {
+			t.Errorf("Unexpected: %v", val)
+		}
+	}
+
+	if fmt.Sprint(cleaned) != "[schema_b]" {
+		t.Errorf("Unexpected: %v", cleaned)
+	}
+
+	if err := c.Cleanup(); err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if fmt.Sprint(cleaned) != "[schema_b schema_c schema_a]" {
+		t.Errorf("Unexpected: %v", cleaned)
+	}
+}
+
+func TestTenantsUnbounded(t *testing.T) {
+	tenants := di.NewTenants[string](di.New(), 0, nil)
+
+	if s := tenants.Get("a"); s != tenants.Get("a") {
+		t.Errorf("Scope of tenant should be kept")
+	}
+}
+
+func TestTenantsSlowEviction(t *testing.T) {
+	type conn string
+
+	var (
+		c       = di.New()
+		release = make(chan struct{})
+		closing = make(chan struct{})
+		evicted = make(chan struct{})
+	)
+
+	tenants := di.NewTenants(c, 1, func(tenant string, s *di.Scope) {
+		di.Set(s.Container, di.OptSetup(func() (conn, error) {
+			return conn(tenant), nil
+		}), di.OptCleanup(func(conn) error {
+			close(closing)
+			<-release
+			return nil
+		}))
+	})
+
+	di.Get[conn](tenants.Get("a").Container)
+	go func() {
+		defer close(evicted)
+		tenants.Get("b")
+	}()
+
+	// eviction of "a" is in progress, "c" is served meanwhile
+	<-closing
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tenants.Get("c")
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("Get shouldn't wait for eviction of other tenant")
+	}
+
+	close(release)
+	<-evicted
+}
@@ -0,0 +1,84 @@
+package di
+
+import "slices"
+
+// SetVersion registers version of entity alongside the others, it's resolved
+// by Get once promoted
+func SetVersion[T any](c *Container, version string, opts ...func(*entityImpl[T])) {
+	SetNamedVersion(c, "", version, opts...)
+}
+
+// SetNamedVersion registers version of named entity
+func SetNamedVersion[T any](c *Container, name, version string, opts ...func(*entityImpl[T])) {
+	SetNamed(c, versionName(name, version), opts...)
+}
+
+// Promote makes Get resolve version of entity, empty version rolls back
+// to the plain registration. Users which already got instance keep it.
+// Versions of built container may be promoted as well.
+func Promote[T any](c *Container, version string) {
+	PromoteNamed[T](c, "", version)
+}
+
+// PromoteNamed makes GetNamed resolve version of named entity
+func PromoteNamed[T any](c *Container, name, version string) {
+	entityName := entityKey[T](c.named(name))
+	if version == "" {
+		c.setRedirect(entityName, "")
+		return
+	}
+
+	c.setRedirect(entityName, entityKey[T](c.named(versionName(name, version))))
+}
+
+func versionName(name, version string) string {
+	return name + "@" + version
+}
+
+// setRedirect of entity key to target one, empty target drops redirect
+func (c *Container) setRedirect(entityName, target string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if target == "" {
+		delete(c.redirects, entityName)
+		return
+	}
+
+	if c.redirects == nil {
+		c.redirects = make(map[string]string)
+	}
+
+	c.redirects[entityName] = target
+}
+
+// redirect entity key through the scopes chain, redirects are followed
+// transitively, e.g. alias of promoted entity resolves its version
+func (c *Container) redirect(entityName string) string {
+	for seen := []string{}; !slices.Contains(seen, entityName); {
+		seen = append(seen, entityName)
+
+		target, ok := c.redirectOf(entityName)
+		if !ok {
+			break
+		}
+
+		entityName = target
+	}
+
+	return entityName
+}
+
+func (c *Container) redirectOf(entityName string) (string, bool) {
+	for owner := c; owner != nil; owner = owner.parent {
+		owner.mu.RLock()
+		target, ok := owner.redirects[entityName]
+		owner.mu.RUnlock()
+
+		if ok {
+			return target, true
+		}
+	}
+
+	return "", false
+}
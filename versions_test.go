@@ -0,0 +1,63 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestPromote(t *testing.T) {
+	type pricing string
+
+	c := di.New()
+
+	di.Set(c, di.OptSetup(func() (pricing, error) {
+		return "v1", nil
+	}))
+	di.SetVersion(c, "v2", di.OptSetup(func() (pricing, error) {
+		return "v2", nil
+	}))
+
+	if val := di.Get[pricing](c); val != "v1" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	di.Promote[pricing](c, "v2")
+	if val := di.Get[pricing](c); val != "v2" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	di.Promote[pricing](c, "")
+	if val := di.Get[pricing](c); val != "v1" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	di.Promote[pricing](c, "v3")
+	if _, err := di.TryGet[pricing](c); err == nil {
+		t.Errorf("TryGet should fail on missing version")
+	}
+}
+
+func TestPromoteBuilt(t *testing.T) {
+	type pricing string
+
+	c, err := di.NewBuilder().Add(func(c *di.Container) {
+		di.Set(c, di.OptSetup(func() (pricing, error) { return "v1", nil }))
+		di.SetVersion(c, "v2", di.OptSetup(func() (pricing, error) { return "v2", nil }))
+	}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	di.Promote[pricing](c, "v2")
+	if val := di.Get[pricing](c); val != "v2" {
+		t.Errorf("Unexpected: %v", val)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Alias should panic on built container")
+		}
+	}()
+	di.Alias[pricing](c, "", "legacy")
+}
@@ -0,0 +1,77 @@
+package di
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// Watchdog runs each setup in its own goroutine and fails entity once setup
+// panics or takes longer than timeout, so single pathological constructor
+// couldn't wedge Init. Timed out setup is abandoned, its goroutine keeps
+// running. Timeout includes nested Gets made by setup. Scope without watchdog
+// of its own uses the one of its parent, timeout < 0 disables it.
+func (c *Container) Watchdog(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.watchdog = timeout
+}
+
+// watchdogTimeout of c or its closest parent having watchdog
+func (c *Container) watchdogTimeout() time.Duration {
+	for owner := c; owner != nil; owner = owner.parent {
+		owner.mu.RLock()
+		timeout := owner.watchdog
+		owner.mu.RUnlock()
+
+		if timeout != 0 {
+			return timeout
+		}
+	}
+
+	return 0
+}
+
+// isolate runs setup in goroutine, failures of nested Gets are propagated
+// as is, other panics become errors
+func isolate[T any](timeout time.Duration, setup func() (T, error)) (T, error) {
+	type result struct {
+		val   T
+		err   error
+		panic any
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		var res result
+		defer func() {
+			if r := recover(); r != nil {
+				if _, ok := r.(resolveError); !ok {
+					r = fmt.Errorf("setup panicked: %v\n%s", r, debug.Stack())
+				}
+				res.panic = r
+			}
+			done <- res
+		}()
+
+		res.val, res.err = setup()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-done:
+		switch r := res.panic.(type) {
+		case nil:
+			return res.val, res.err
+		case resolveError:
+			panic(r)
+		default:
+			return empty[T](), r.(error)
+		}
+	case <-timer.C:
+		return empty[T](), fmt.Errorf("setup timed out after %v", timeout)
+	}
+}
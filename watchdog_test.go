@@ -0,0 +1,54 @@
+package di_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/irr123/di"
+)
+
+func TestWatchdog(t *testing.T) {
+	var (
+		c       = di.New()
+		errTest = errors.New("test")
+		hang    = make(chan struct{})
+	)
+	defer close(hang)
+
+	c.Watchdog(50 * time.Millisecond)
+
+	di.Set(c, di.OptSetup(func() (string, error) {
+		<-hang
+		return "never", nil
+	}))
+	di.Set(c, di.OptSetup(func() (int, error) {
+		panic("boom")
+	}))
+	di.Set(c, di.OptSetup(func() (float64, error) {
+		return 0, errTest
+	}))
+	di.Set(c, di.OptSetupC(func(c *di.Container) (bool, error) {
+		return di.Get[float64](c) == 0, nil
+	}))
+	di.Set(c, di.OptSetup(func() (uint, error) {
+		return 42, nil
+	}))
+
+	if _, err := di.TryGet[string](c); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if _, err := di.TryGet[int](c); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if _, err := di.TryGet[bool](c); !errors.Is(err, errTest) {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if val := di.Get[uint](c); val != 42 {
+		t.Errorf("Unexpected: %v", val)
+	}
+}
@@ -0,0 +1,51 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// With builds fresh instance of T, even if T is singleton, passes it to f and
+// cleans it up right after f returns, e.g. per batch session. Instance isn't
+// cached and its cleanup isn't left pending in container.
+func With[T any](c *Container, f func(T) error) error {
+	return WithNamed(c, "", f)
+}
+
+// WithNamed builds fresh instance of named T for f, see With
+func WithNamed[T any](c *Container, name string, f func(T) error) (err error) {
+	defer recoverResolveError(&err)
+
+	var (
+		fresh     entity
+		built     any
+		cleanupFn func() error
+	)
+
+	// fresh instance is resolved as Get resolves entity, checks and
+	// interceptors included
+	val, err := c.resolveBy(entityKey[T](c.named(name)), func(entityName string, found entity, _ *Container) (any, error) {
+		fresh = found.clone()
+
+		var err error
+		if built, cleanupFn, err = fresh.setup(c, c.step(fresh)); err != nil {
+			return nil, fmt.Errorf("setup dependency %s: %w", entityName, err)
+		}
+
+		return built, nil
+	})
+	if cleanupFn != nil {
+		defer func() {
+			drainErr := fresh.drain(context.Background(), built)
+			_, cleanupErr := c.runCleanup(cleanup{fn: cleanupFn, val: built, source: fresh, entity: fresh.info()})
+			err = errors.Join(err, drainErr, cleanupErr)
+		}()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return f(valueOf[T](val))
+}
@@ -0,0 +1,59 @@
+package di_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/irr123/di"
+)
+
+func TestWith(t *testing.T) {
+	type session struct{ id int }
+
+	var (
+		c       = di.New()
+		log     = []string{}
+		errTest = errors.New("test")
+		opened  = 0
+	)
+
+	di.Set(c, di.OptSetup(func() (*session, error) {
+		opened++
+		return &session{id: opened}, nil
+	}), di.OptCleanup(func(s *session) error {
+		log = append(log, fmt.Sprint("close ", s.id))
+		return nil
+	}))
+
+	singleton := di.Get[*session](c)
+
+	for range 2 {
+		if err := di.With(c, func(s *session) error {
+			if s == singleton {
+				t.Errorf("With should build fresh instance")
+			}
+
+			log = append(log, fmt.Sprint("use ", s.id))
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := di.With(c, func(*session) error { return errTest }); !errors.Is(err, errTest) {
+		t.Errorf("Unexpected: %v", err)
+	}
+
+	if fmt.Sprint(log) != "[use 2 close 2 use 3 close 3 close 4]" {
+		t.Errorf("Unexpected: %v", log)
+	}
+
+	if pending := c.Pending(); len(pending) != 1 {
+		t.Errorf("Unexpected: %v", pending)
+	}
+
+	if err := di.With(c, func(int) error { return nil }); err == nil {
+		t.Errorf("With should fail on missing entity")
+	}
+}